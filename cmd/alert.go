@@ -6,8 +6,10 @@ import (
 	"regexp"
 	"slices"
 	"strings"
+	"text/template"
 
 	"github.com/NETWAYS/check_prometheus/internal/alert"
+	"github.com/NETWAYS/check_prometheus/internal/output"
 	"github.com/NETWAYS/go-check"
 	"github.com/NETWAYS/go-check/perfdata"
 	"github.com/NETWAYS/go-check/result"
@@ -16,15 +18,17 @@ import (
 )
 
 type AlertConfig struct {
-	AlertName     []string
-	Group         []string
-	ExcludeAlerts []string
-	ExcludeLabels []string
-	IncludeLabels []string
-	ProblemsOnly  bool
-	FlipExitState bool
-	StateLabelKey string
-	NoAlertsState string
+	AlertName      []string
+	Group          []string
+	ExcludeAlerts  []string
+	ExcludeLabels  []string
+	IncludeLabels  []string
+	ProblemsOnly   bool
+	FlipExitState  bool
+	StateLabelKey  string
+	NoAlertsState  string
+	OutputTemplate string
+	Humanize       string
 }
 
 var cliAlertConfig AlertConfig
@@ -54,6 +58,21 @@ inactive = 0`,
 			check.ExitError(fmt.Errorf("invalid value for --no-alerts-state: %s", cliAlertConfig.NoAlertsState))
 		}
 
+		if cliAlertConfig.Humanize != "" {
+			if err := output.ValidateMode(cliAlertConfig.Humanize); err != nil {
+				check.ExitError(err)
+			}
+		}
+
+		var outputTemplate *template.Template
+
+		if cliAlertConfig.OutputTemplate != "" {
+			outputTemplate, err = output.NewTemplate("output", cliAlertConfig.OutputTemplate)
+			if err != nil {
+				check.ExitError(fmt.Errorf("invalid value for --output-template: %w", err))
+			}
+		}
+
 		var (
 			counterFiring   int
 			counterPending  int
@@ -172,7 +191,7 @@ inactive = 0`,
 				}
 
 				_ = sc.SetState(rlStatus)
-				sc.Output = rl.GetOutput()
+				sc.Output = alertOutput(rl, rlStatus, outputTemplate)
 				overall.AddSubcheck(sc)
 			}
 
@@ -202,7 +221,7 @@ inactive = 0`,
 					_ = sc.SetState(rlStatus)
 					// Set the alert in the internal Type to generate the output
 					rl.Alert = alert
-					sc.Output = rl.GetOutput()
+					sc.Output = alertOutput(rl, rlStatus, outputTemplate)
 					overall.AddSubcheck(sc)
 				}
 			}
@@ -277,6 +296,15 @@ func init() {
 	fs.StringVarP(&cliAlertConfig.StateLabelKey, "label-key-state", "S", "",
 		"Use the given AlertRule label to override the exit state for firing alerts."+
 			"\nIf this flag is set the plugin looks for the strings 'warning/critical/ok' in the provided label key")
+
+	fs.StringVar(&cliAlertConfig.Humanize, "humanize", "",
+		"Format the value of the alerts in the output ("+strings.Join(output.Modes, ", ")+")."+
+			"\nbytes and si use binary and SI prefixes, duration formats seconds and timestamp formats unix timestamps relative to now")
+
+	fs.StringVar(&cliAlertConfig.OutputTemplate, "output-template", "",
+		"Go template for the output of each alert e.g.: '{{.Name}} on {{.Labels.instance}} is {{.AlertState}} - {{.Annotations.summary}}'."+
+			"\nAvailable fields: .Name .Labels .Annotations .Value .State .AlertState .ActiveAt .ActiveSince"+
+			"\nAvailable functions: humanize humanize1024 humanizeDuration humanizePercentage humanizeTimestamp")
 }
 
 // Function to convert state to integer.
@@ -296,6 +324,24 @@ func convertStateToInt(state string) (int, error) {
 	}
 }
 
+// alertOutput returns the output of an alert, rendered with the output template if set
+func alertOutput(rl alert.Rule, status int, tmpl *template.Template) string {
+	if tmpl == nil && cliAlertConfig.Humanize != "" {
+		return rl.GetHumanizedOutput(cliAlertConfig.Humanize)
+	}
+
+	if tmpl == nil {
+		return rl.GetOutput()
+	}
+
+	out, err := rl.GetTemplateOutput(tmpl, status)
+	if err != nil {
+		return fmt.Sprintf("%s - invalid output template: %s", rl.GetOutput(), err.Error())
+	}
+
+	return out
+}
+
 // Matches a list of regular expressions against a string.
 func matches(input string, regexToExclude []string) (bool, error) {
 	for _, regex := range regexToExclude {
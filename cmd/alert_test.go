@@ -327,6 +327,37 @@ exit status 2
 |total=3 firing=1 pending=1 inactive=1
 
 exit status 1
+`,
+		},
+		{
+			name: "alert-output-template",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write(loadTestdata(alertTestDataSet1))
+			})),
+			args: []string{"run", "../main.go", "alert", "--output-template", "{{.Name}} ({{.AlertState}}/{{.State}}){{with .Labels.instance}} on {{.}}{{end}}: {{.Annotations.summary}} {{humanizePercentage .Value}}"},
+			expected: `[CRITICAL] - 3 Alerts: 1 Firing - 1 Pending - 1 Inactive
+\_ [OK] HostOutOfMemory (inactive/OK): Foo 0%
+\_ [WARNING] SqlAccessDeniedRate (pending/WARNING) on localhost: MySQL 40.34%
+\_ [CRITICAL] BlackboxTLS (firing/CRITICAL) on https://localhost:443: TLS -6.065e+08%
+|total=3 firing=1 pending=1 inactive=1
+
+exit status 2
+`,
+		},
+		{
+			name: "alert-humanize",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write(loadTestdata(alertTestDataSet1))
+			})),
+			args: []string{"run", "../main.go", "alert", "--problems", "--humanize", "si"},
+			expected: `[CRITICAL] - 2 Alerts: 1 Firing - 1 Pending - 0 Inactive
+\_ [WARNING] [SqlAccessDeniedRate] - Job: [mysql] on Instance: [localhost] is pending - value: 403.4m - {"alertname":"SqlAccessDeniedRate","instance":"localhost","job":"mysql","severity":"warning"}
+\_ [CRITICAL] [BlackboxTLS] - Job: [blackbox] on Instance: [https://localhost:443] is firing - value: -6.065M - {"alertname":"TLS","instance":"https://localhost:443","job":"blackbox","severity":"critical"}
+|total=2 firing=1 pending=1 inactive=0
+
+exit status 2
 `,
 		},
 	}
@@ -1,40 +1,1646 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
+	"os"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
 
+	"github.com/NETWAYS/check_prometheus/internal/client"
+	"github.com/NETWAYS/check_prometheus/internal/output"
+	"github.com/NETWAYS/check_prometheus/internal/query"
 	"github.com/NETWAYS/go-check"
 	"github.com/NETWAYS/go-check/perfdata"
 	goresult "github.com/NETWAYS/go-check/result"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
 	"github.com/spf13/cobra"
 )
 
 type QueryConfig struct {
-	RawQuery string
+	Queries          []string
+	QueryThresholds  []string
+	QueryLibrary     string
+	QueryName        string
+	QueryDescription string
+	Vars             []string
+	ShowAll          bool
+	UnixTime         bool
+
 	Warning  string
 	Critical string
-	ShowAll  bool
-	UnixTime bool
+
+	WarningCount    string
+	CriticalCount   string
+	WarningPercent  string
+	CriticalPercent string
+
+	Thresholds    []string
+	ValueMap      string
+	StateLabel    string
+	StateLabelMap string
+	UnmappedState string
+
+	Numerator       string
+	Denominator     string
+	JoinLabels      []string
+	ZeroDenominator string
+
+	IncludeLabels []string
+	ExcludeLabels []string
+
+	Humanize       string
+	OutputTemplate string
+	PerfdataLabel  string
+	Uom            string
+	Min            string
+	Max            string
+
+	Aggregate           string
+	Percentile          float64
+	Histogram           string
+	HistogramWindow     string
+	HistogramBy         []string
+	HistogramQuantiles  []string
+	HistogramView       string
+
+	CompareOffset       string
+	CompareMode         string
+	CompareMissingState string
+
+	ForecastTarget    string
+	ForecastRange     string
+	ForecastStep      string
+	ForecastDirection string
+
+	DutyCycle         string
+	DutyCycleStep     string
+	WarningDutyCycle  string
+	CriticalDutyCycle string
+
+	Time          string
+	QueryTimeout  string
+	LookbackDelta string
+
+	WarningsState string
+
+	Stats             bool
+	WarningEvalTime   string
+	CriticalEvalTime  string
+	WarningQueueTime  string
+	CriticalQueueTime string
+	WarningSamples    string
+	CriticalSamples   string
+
+	MaxAge     string
+	StaleState string
+
+	EmptyState   string
+	EmptyMessage string
+
+	MinSeries        int
+	MaxSeries        int
+	SeriesCountState string
+
+	ExpectSeries     []string
+	ExpectSeriesFile string
+	MissingState     string
+	ReportUnexpected bool
+	UnexpectedState  string
+
+	ExpectString        string
+	StringMatchState    string
+	StringMismatchState string
+
+	// Values parsed from the flags in PreRun, so that they are parsed only once
+	expectedSeries []model.LabelSet
+	valueMap       query.ValueMap
+	stateLabelMap  query.StateLabelMap
+	filter         *query.LabelFilter
+	outputTemplate *template.Template
+	perfdataLabel  *template.Template
+	quantiles      []*query.Quantile
+}
+
+type User struct {
+	Name       string
+	Occupation string
+}
+
+var cliQueryConfig QueryConfig
+
+var replacer = strings.NewReplacer("{", "_", "}", "", "\"", "", ",", "_", " ", "")
+
+func generateMetricOutput(metric string, value string) string {
+	// Format the metric and RC output for console output
+	return fmt.Sprintf(" %s - value: %s", metric, value)
+}
+
+// applyOutputTemplate replaces the output of a series with the output of --output-template if set.
+// The value is the value that is evaluated against the thresholds.
+// It has to be applied after all changes of the state, so that the template gets the final state.
+func applyOutputTemplate(partial *goresult.PartialResult, metric model.Metric, value float64) {
+	if cliQueryConfig.outputTemplate == nil {
+		return
+	}
+
+	out, err := output.Render(cliQueryConfig.outputTemplate, output.Data{
+		Name:   string(metric[model.MetricNameLabel]),
+		Labels: output.LabelMap(metric),
+		Value:  value,
+		State:  check.StatusText(partial.GetStatus()),
+	})
+	if err != nil {
+		partial.Output += " - invalid output template: " + err.Error()
+		return
+	}
+
+	partial.Output = out
+}
+
+// formatValue formats a value for the plugin output, humanized with the mode of --humanize if set
+// and followed by the text of --value-map
+func formatValue(value float64) string {
+	formatted := model.SampleValue(value).String()
+
+	if cliQueryConfig.Humanize != "" {
+		formatted = output.HumanizeMode(cliQueryConfig.Humanize, value, time.Now())
+	}
+
+	if cliQueryConfig.valueMap != nil {
+		if mapping, ok := cliQueryConfig.valueMap.Lookup(value); ok && mapping.Text != "" {
+			formatted += " (" + mapping.Text + ")"
+		}
+	}
+
+	return formatted
+}
+
+func generateAggregateOutput(metric string, aggregation string, value string) string {
+	// Format the metric, the used aggregation and RC output for console output
+	return fmt.Sprintf(" %s - %s: %s", metric, aggregation, value)
+}
+
+// evaluateThresholds returns the state of a value for the given thresholds.
+// If --value-map is set, the state of the value is taken from the map instead.
+func evaluateThresholds(value float64, warning, critical *check.Threshold) int {
+	if cliQueryConfig.valueMap != nil {
+		// We already make sure it's valid
+		unmappedState, _ := convertStateToInt(cliQueryConfig.UnmappedState)
+
+		if mapping, ok := cliQueryConfig.valueMap.Lookup(value); ok {
+			return mapping.State
+		}
+
+		return unmappedState
+	}
+
+	if critical.DoesViolate(value) {
+		return check.Critical
+	}
+
+	if warning.DoesViolate(value) {
+		return check.Warning
+	}
+
+	return check.OK
+}
+
+// evaluateString matches a string result against the expected regular expression
+// and maps the match or mismatch to the configured state
+func evaluateString(str *model.String) goresult.PartialResult {
+	partial := goresult.NewPartialResult()
+
+	// We already made sure the regular expression and the states are valid
+	re := regexp.MustCompile(cliQueryConfig.ExpectString)
+
+	matchState, _ := convertStateToInt(cliQueryConfig.StringMatchState)
+	mismatchState, _ := convertStateToInt(cliQueryConfig.StringMismatchState)
+
+	if re.MatchString(str.Value) {
+		_ = partial.SetState(matchState)
+		partial.Output = fmt.Sprintf(" string - value: %s matches %s", str.Value, cliQueryConfig.ExpectString)
+	} else {
+		_ = partial.SetState(mismatchState)
+		partial.Output = fmt.Sprintf(" string - value: %s does not match %s", str.Value, cliQueryConfig.ExpectString)
+	}
+
+	return partial
+}
+
+// compareVector performs the query again at the configured offset and evaluates the difference
+// between the current and the previous value of each series against the thresholds
+func compareVector(ctx context.Context, c *client.Client, q query.NamedQuery, current model.Vector, now time.Time, overrides []*query.ThresholdOverride, overall *goresult.Overall) (v1.Warnings, error) {
+	// We already make sure these are valid
+	offset, _ := model.ParseDuration(cliQueryConfig.CompareOffset)
+	missingState, _ := convertStateToInt(cliQueryConfig.CompareMissingState)
+
+	result, warnings, err := c.API.Query(ctx, q.Expr, now.Add(-time.Duration(offset)), queryOptions()...)
+	if err != nil {
+		return warnings, err
+	}
+
+	previousVal, ok := result.(model.Vector)
+	if !ok {
+		return warnings, fmt.Errorf("%s value results are not supported with --compare-offset", result.Type())
+	}
+
+	previous := query.IndexVector(previousVal)
+
+	uom := ""
+	formatDelta := formatValue
+
+	if cliQueryConfig.CompareMode == query.ComparePercent {
+		uom = "%"
+		formatDelta = func(v float64) string { return model.SampleValue(v).String() }
+	}
+
+	for _, sample := range current {
+		partial := goresult.NewPartialResult()
+		label := sample.Metric.String()
+
+		prev, ok := previous[sample.Metric.Fingerprint()]
+		if !ok {
+			_ = partial.SetState(missingState)
+			partial.Output = fmt.Sprintf("%s - %s ago: no value", generateMetricOutput(label, formatValue(float64(sample.Value))), offset)
+			overall.AddSubcheck(partial)
+
+			continue
+		}
+
+		sampleWarn, sampleCrit := query.SelectThresholds(overrides, sample.Metric, q.Warning, q.Critical)
+		delta := query.Difference(cliQueryConfig.CompareMode, float64(sample.Value), float64(prev.Value))
+
+		_ = partial.SetState(evaluateThresholds(delta, sampleWarn, sampleCrit))
+
+		partial.Output = fmt.Sprintf("%s - %s ago: %s - change: %s%s",
+			generateMetricOutput(label, formatValue(float64(sample.Value))), offset, formatValue(float64(prev.Value)), formatDelta(delta), uom)
+		applyOutputTemplate(&partial, sample.Metric, delta)
+
+		pdLabel := perfdataMetric(sample.Metric)
+
+		currentPd := generatePerfdata(pdLabel, float64(sample.Value), nil, nil)
+		applyValueUnit(&currentPd)
+
+		previousPd := generatePerfdata(pdLabel+"_previous", float64(prev.Value), nil, nil)
+		applyValueUnit(&previousPd)
+
+		deltaPd := generatePerfdata(pdLabel+"_delta", delta, sampleWarn, sampleCrit)
+		deltaPd.Uom = uom
+
+		if uom == "" {
+			deltaPd.Uom = cliQueryConfig.Uom
+		}
+
+		for _, pd := range []perfdata.Perfdata{currentPd, previousPd, deltaPd} {
+			// Generate Perfdata only for valid values
+			if v := pd.Value.(float64); !math.IsInf(v, 0) && !math.IsNaN(v) {
+				partial.Perfdata.Add(&pd)
+			}
+		}
+
+		overall.AddSubcheck(partial)
+	}
+
+	return warnings, nil
+}
+
+// evaluateSeriesCount checks the number of returned series against --min-series and --max-series
+func evaluateSeriesCount(count int) goresult.PartialResult {
+	partial := goresult.NewPartialResult()
+	// We already make sure it's valid
+	violationState, _ := convertStateToInt(cliQueryConfig.SeriesCountState)
+
+	switch {
+	case count < cliQueryConfig.MinSeries:
+		_ = partial.SetState(violationState)
+		partial.Output = fmt.Sprintf("series count: %d - expected at least %d", count, cliQueryConfig.MinSeries)
+	case cliQueryConfig.MaxSeries >= 0 && count > cliQueryConfig.MaxSeries:
+		_ = partial.SetState(violationState)
+		partial.Output = fmt.Sprintf("series count: %d - expected at most %d", count, cliQueryConfig.MaxSeries)
+	default:
+		_ = partial.SetState(check.OK)
+		partial.Output = fmt.Sprintf("series count: %d", count)
+	}
+
+	partial.Perfdata.Add(&perfdata.Perfdata{Label: "series", Value: count})
+
+	return partial
+}
+
+// aggregateSampleStream reduces all values of a SampleStream with the configured
+// aggregation function and evaluates the result against the thresholds
+func aggregateSampleStream(samplestream *model.SampleStream, warning, critical *check.Threshold, now time.Time) goresult.PartialResult {
+	partial := goresult.NewPartialResult()
+	aggregation := query.AggregationLabel(cliQueryConfig.Aggregate, cliQueryConfig.Percentile)
+
+	numberValue, err := query.Aggregate(cliQueryConfig.Aggregate, samplestream.Values, cliQueryConfig.Percentile)
+	if err != nil {
+		_ = partial.SetState(check.Unknown)
+		partial.Output = generateAggregateOutput(samplestream.Metric.String(), aggregation, err.Error())
+
+		return partial
+	}
+
+	_ = partial.SetState(evaluateThresholds(numberValue, warning, critical))
+	evaluateStateLabel(&partial, samplestream.Metric)
+
+	// Format the metric and RC output for console output
+	partial.Output = generateAggregateOutput(samplestream.Metric.String(), aggregation, formatValue(numberValue))
+
+	// Generate Perfdata from the aggregated value
+	if !math.IsInf(numberValue, 0) && !math.IsNaN(numberValue) {
+		pd := generatePerfdata(perfdataMetric(samplestream.Metric)+"_"+aggregation, numberValue, warning, critical)
+		applyValueUnit(&pd)
+		partial.Perfdata.Add(&pd)
+	}
+
+	if cliQueryConfig.MaxAge != "" && len(samplestream.Values) > 0 {
+		evaluateAge(&partial, samplestream.Metric, samplestream.Values[len(samplestream.Values)-1].Timestamp, now)
+	}
+
+	applyOutputTemplate(&partial, samplestream.Metric, numberValue)
+
+	return partial
+}
+
+// evaluateHistogram evaluates the count, sum or the quantiles of --histogram-quantile of a native histogram
+// against the thresholds. The count, sum and the quantiles are added as perfdata.
+func evaluateHistogram(metric model.Metric, histogram *model.SampleHistogram, timestamp model.Time, warning, critical *check.Threshold, overrides []*query.ThresholdOverride, now time.Time) goresult.PartialResult {
+	partial := goresult.NewPartialResult()
+
+	count, sum := float64(histogram.Count), float64(histogram.Sum)
+
+	countPd := generatePerfdata(perfdataMetric(metric)+"_"+query.HistogramViewCount, count, nil, nil)
+	sumPd := generatePerfdata(perfdataMetric(metric)+"_"+query.HistogramViewSum, sum, nil, nil)
+	// The count is a number of observations, all other values have the unit of the observations
+	applyValueUnit(&sumPd)
+
+	pds := []perfdata.Perfdata{countPd, sumPd}
+
+	var (
+		states    []int
+		evaluated []string
+		// The value of the output template, the first quantile for the quantile view
+		templateValue float64
+	)
+
+	switch cliQueryConfig.HistogramView {
+	case query.HistogramViewCount, query.HistogramViewSum:
+		templateValue = count
+		if cliQueryConfig.HistogramView == query.HistogramViewSum {
+			templateValue = sum
+		}
+
+		seriesWarn, seriesCrit := query.SelectThresholds(overrides, metric, warning, critical)
+		states = append(states, evaluateThresholds(templateValue, seriesWarn, seriesCrit))
+		evaluated = append(evaluated, cliQueryConfig.HistogramView+": "+formatValue(templateValue))
+
+		if cliQueryConfig.HistogramView == query.HistogramViewCount {
+			pds[0].Warn, pds[0].Crit = seriesWarn, seriesCrit
+		} else {
+			pds[1].Warn, pds[1].Crit = seriesWarn, seriesCrit
+		}
+	}
+
+	for i, q := range cliQueryConfig.quantiles {
+		// We already make sure the quantiles are valid
+		value, _ := query.HistogramQuantile(q.Quantile, histogram)
+
+		pd := generatePerfdata(perfdataMetric(metric)+"_quantile_"+string(q.Label()), value, nil, nil)
+		applyValueUnit(&pd)
+
+		if cliQueryConfig.HistogramView == query.HistogramViewQuantile {
+			quantileMetric := metric.Clone()
+			quantileMetric[model.QuantileLabel] = q.Label()
+
+			pd.Warn, pd.Crit = quantileThresholds(q, quantileMetric, warning, critical, overrides)
+			states = append(states, evaluateThresholds(value, pd.Warn, pd.Crit))
+			evaluated = append(evaluated, fmt.Sprintf("quantile %s: %s", q.Label(), formatValue(value)))
+
+			if i == 0 {
+				templateValue = value
+			}
+		}
+
+		pds = append(pds, pd)
+	}
+
+	_ = partial.SetState(goresult.WorstState(states...))
+	evaluateStateLabel(&partial, metric)
+
+	partial.Output = fmt.Sprintf(" %s - %s (count: %s, sum: %s)",
+		metric, strings.Join(evaluated, ", "), histogram.Count, formatValue(sum))
+
+	for _, pd := range pds {
+		if v := pd.Value.(float64); !math.IsInf(v, 0) && !math.IsNaN(v) {
+			partial.Perfdata.Add(&pd)
+		}
+	}
+
+	if cliQueryConfig.MaxAge != "" {
+		evaluateAge(&partial, metric, timestamp, now)
+	}
+
+	applyOutputTemplate(&partial, metric, templateValue)
+
+	return partial
+}
+
+// quantileThresholds returns the thresholds of a quantile of a histogram series with the quantile label.
+// Missing thresholds of the quantile fall back to the given thresholds, a matching --threshold takes precedence.
+func quantileThresholds(q *query.Quantile, metric model.Metric, warning, critical *check.Threshold, overrides []*query.ThresholdOverride) (*check.Threshold, *check.Threshold) {
+	if q.Warning != nil {
+		warning = q.Warning
+	}
+
+	if q.Critical != nil {
+		critical = q.Critical
+	}
+
+	return query.SelectThresholds(overrides, metric, warning, critical)
+}
+
+// parseQueries parses the --query and --query-threshold flags and substitutes the placeholders with the --var variables.
+// Queries without their own thresholds use the given global thresholds.
+func parseQueries(warning, critical *check.Threshold) ([]query.NamedQuery, error) {
+	queries := make([]query.NamedQuery, 0, len(cliQueryConfig.Queries))
+	names := make(map[string]int, len(cliQueryConfig.Queries))
+
+	vars, err := query.ParseVars(cliQueryConfig.Vars)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, spec := range cliQueryConfig.Queries {
+		q := query.ParseNamedQuery(spec)
+		q.Warning, q.Critical = warning, critical
+
+		q.Expr, err = query.Substitute(q.Expr, vars)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(cliQueryConfig.Queries) > 1 && q.Name == "" {
+			return nil, fmt.Errorf("please specify a name for each query when using multiple queries (--query name=expr): %s", spec)
+		}
+
+		if _, ok := names[q.Name]; ok && q.Name != "" {
+			return nil, fmt.Errorf("duplicate query name: %s", q.Name)
+		}
+
+		names[q.Name] = len(queries)
+		queries = append(queries, q)
+	}
+
+	for _, spec := range cliQueryConfig.QueryThresholds {
+		name, w, c, err := query.ParseQueryThreshold(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		i, ok := names[name]
+		if !ok || name == "" {
+			return nil, fmt.Errorf("no query with the name '%s' for threshold: %s", name, spec)
+		}
+
+		if w != nil {
+			queries[i].Warning = w
+		}
+
+		if c != nil {
+			queries[i].Critical = c
+		}
+	}
+
+	return queries, nil
+}
+
+// substituteVars substitutes the placeholders of --numerator, --denominator and --histogram with the --var variables
+func substituteVars() error {
+	vars, err := query.ParseVars(cliQueryConfig.Vars)
+	if err != nil {
+		return err
+	}
+
+	for _, expr := range []*string{&cliQueryConfig.Numerator, &cliQueryConfig.Denominator, &cliQueryConfig.Histogram} {
+		*expr, err = query.Substitute(*expr, vars)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyLibraryQuery loads the query of --query-name from the query library.
+// The thresholds and the perfdata label of the library are used, unless they are set explicitly.
+func applyLibraryQuery(cmd *cobra.Command) error {
+	if cliQueryConfig.QueryLibrary == "" {
+		return errors.New("--query-name requires --query-library")
+	}
+
+	f, err := os.Open(cliQueryConfig.QueryLibrary)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	library, err := query.ReadLibrary(f)
+	if err != nil {
+		return err
+	}
+
+	lq, err := library.Lookup(cliQueryConfig.QueryName)
+	if err != nil {
+		return err
+	}
+
+	vars, err := query.ParseVars(cliQueryConfig.Vars)
+	if err != nil {
+		return err
+	}
+
+	// The placeholders of the expression are substituted like the ones of --query
+	cliQueryConfig.Queries = []string{lq.Expr}
+	cliQueryConfig.QueryDescription = query.SubstituteText(lq.Description, vars)
+
+	if lq.Warning != "" && !cmd.Flags().Changed("warning") {
+		cliQueryConfig.Warning = lq.Warning
+	}
+
+	if lq.Critical != "" && !cmd.Flags().Changed("critical") {
+		cliQueryConfig.Critical = lq.Critical
+	}
+
+	if cliQueryConfig.PerfdataLabel == "" {
+		cliQueryConfig.PerfdataLabel = lq.PerfdataLabel
+	}
+
+	return nil
+}
+
+// exitQuery exits with the result of a single query
+func exitQuery(overall *goresult.Overall, warnings v1.Warnings, infos []string, err error) {
+	if err != nil {
+		check.ExitError(err)
+	}
+
+	if cliQueryConfig.WarningsState != "" {
+		for _, partial := range evaluateAnnotations(warnings, infos) {
+			overall.AddSubcheck(partial)
+		}
+	}
+
+	if cliQueryConfig.QueryDescription != "" {
+		overall.Summary = fmt.Sprintf("%s - %s", cliQueryConfig.QueryDescription, overall.GetSummary())
+	}
+
+	if cliQueryConfig.WarningsState == "" && len(warnings) != 0 {
+		appendum := fmt.Sprintf("HTTP Warnings: %v", strings.Join(warnings, ", "))
+		overall.Summary = overall.GetSummary() + "\n" + appendum
+	}
+
+	check.ExitRaw(overall.GetStatus(), overall.GetOutput())
+}
+
+// evaluateAnnotations returns a PartialResult with the state of --warnings-state
+// for each warning and info annotation returned by the API
+func evaluateAnnotations(warnings v1.Warnings, infos []string) []goresult.PartialResult {
+	// We already make sure it's valid
+	state, _ := convertStateToInt(cliQueryConfig.WarningsState)
+
+	partials := make([]goresult.PartialResult, 0, len(warnings)+len(infos))
+
+	for _, annotation := range []struct {
+		kind     string
+		messages []string
+	}{
+		{"warning", warnings},
+		{"info", infos},
+	} {
+		for _, message := range annotation.messages {
+			partial := goresult.NewPartialResult()
+			_ = partial.SetState(state)
+			partial.Output = fmt.Sprintf("%s: %s", annotation.kind, message)
+			partials = append(partials, partial)
+		}
+	}
+
+	return partials
+}
+
+// queryVector performs a query that is expected to return an instant vector
+func queryVector(ctx context.Context, c *client.Client, expr string, ts time.Time) (model.Vector, v1.Warnings, error) {
+	result, warnings, err := c.API.Query(ctx, expr, ts, queryOptions()...)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	vectorVal, ok := result.(model.Vector)
+	if !ok {
+		return nil, warnings, fmt.Errorf("%s value results are not supported for '%s', expected a vector", result.Type(), expr)
+	}
+
+	return vectorVal, warnings, nil
+}
+
+// ratioQuery performs the numerator and denominator queries, joins their series
+// and evaluates the ratio of each series pair against the thresholds
+func ratioQuery(ctx context.Context, c *client.Client, warning, critical *check.Threshold, overrides []*query.ThresholdOverride) (*goresult.Overall, v1.Warnings, error) {
+	// We already make sure it's valid
+	zeroState, _ := convertStateToInt(cliQueryConfig.ZeroDenominator)
+
+	now := queryTime()
+
+	var numeratorResponse, denominatorResponse client.Response
+
+	numerator, warnings, err := queryVector(client.WithResponse(ctx, &numeratorResponse), c, cliQueryConfig.Numerator, now)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	numerator, filtered := filterVector(numerator)
+
+	denominator, denominatorWarnings, err := queryVector(client.WithResponse(ctx, &denominatorResponse), c, cliQueryConfig.Denominator, now)
+	warnings = append(warnings, denominatorWarnings...)
+
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	pairs, unmatched, err := query.JoinVectors(numerator, denominator, cliQueryConfig.JoinLabels)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	overall := &goresult.Overall{}
+
+	for _, pair := range pairs {
+		partial := goresult.NewPartialResult()
+
+		metric := pair.Metric.Clone()
+		metric[model.MetricNameLabel] = "ratio"
+
+		if pair.Denominator.Value == 0 {
+			_ = partial.SetState(zeroState)
+			partial.Output = fmt.Sprintf(" %s - %s / %s - denominator is 0", metric, pair.Numerator.Value, pair.Denominator.Value)
+			overall.AddSubcheck(partial)
+
+			continue
+		}
+
+		ratio := float64(pair.Numerator.Value) / float64(pair.Denominator.Value)
+		pairWarn, pairCrit := query.SelectThresholds(overrides, pair.Metric, warning, critical)
+
+		_ = partial.SetState(evaluateThresholds(ratio, pairWarn, pairCrit))
+
+		partial.Output = fmt.Sprintf("%s (%s / %s)",
+			generateMetricOutput(metric.String(), formatValue(ratio)), formatValue(float64(pair.Numerator.Value)), formatValue(float64(pair.Denominator.Value)))
+		applyOutputTemplate(&partial, metric, ratio)
+
+		if !math.IsInf(ratio, 0) && !math.IsNaN(ratio) {
+			pd := generatePerfdata(perfdataMetric(metric), ratio, pairWarn, pairCrit)
+			applyValueUnit(&pd)
+			partial.Perfdata.Add(&pd)
+		}
+
+		overall.AddSubcheck(partial)
+	}
+
+	series := make([]model.Metric, 0, len(pairs)+len(unmatched))
+	for _, pair := range pairs {
+		series = append(series, pair.Metric)
+	}
+
+	for _, sample := range unmatched {
+		partial := goresult.NewPartialResult()
+		_ = partial.SetState(check.Unknown)
+		partial.Output = fmt.Sprintf(" %s - no matching denominator series", sample.Metric)
+		overall.AddSubcheck(partial)
+
+		series = append(series, sample.Metric)
+	}
+
+	evaluateSeriesResults(overall, series, filtered)
+
+	if isStatsMode() {
+		overall.AddSubcheck(evaluateStats(&numeratorResponse, &denominatorResponse))
+	}
+
+	return overall, warnings, nil
+}
+
+// histogramQuery calculates the quantiles of --histogram-quantile of a classic histogram from its bucket series
+// and evaluates each quantile of each group against the thresholds
+func histogramQuery(ctx context.Context, c *client.Client, warning, critical *check.Threshold, overrides []*query.ThresholdOverride) (*goresult.Overall, v1.Warnings, error) {
+	expr := query.BucketQuery(cliQueryConfig.Histogram, cliQueryConfig.HistogramWindow, cliQueryConfig.HistogramBy)
+
+	var response client.Response
+
+	vectorVal, warnings, err := queryVector(client.WithResponse(ctx, &response), c, expr, queryTime())
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	allGroups, err := query.GroupBuckets(vectorVal)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	groups := make([]query.BucketGroup, 0, len(allGroups))
+
+	for _, group := range allGroups {
+		if cliQueryConfig.filter.Keep(group.Metric) {
+			groups = append(groups, group)
+		}
+	}
+
+	filtered := len(allGroups) - len(groups)
+
+	name, _, _ := strings.Cut(cliQueryConfig.Histogram, "{")
+
+	overall := &goresult.Overall{}
+
+	for _, group := range groups {
+		for _, q := range cliQueryConfig.quantiles {
+			partial := goresult.NewPartialResult()
+
+			// Each quantile is a series of its own with the quantile label, so that --threshold can match it as well
+			metric := group.Metric.Clone()
+			metric[model.MetricNameLabel] = model.LabelValue(strings.TrimSpace(name))
+			metric[model.QuantileLabel] = q.Label()
+
+			numberValue, err := query.BucketQuantile(q.Quantile, group.Buckets)
+			if err != nil {
+				_ = partial.SetState(check.Unknown)
+				partial.Output = fmt.Sprintf(" %s - %s", metric, err.Error())
+				overall.AddSubcheck(partial)
+
+				continue
+			}
+
+			metricWarn, metricCrit := quantileThresholds(q, metric, warning, critical, overrides)
+
+			_ = partial.SetState(evaluateThresholds(numberValue, metricWarn, metricCrit))
+
+			partial.Output = generateMetricOutput(metric.String(), formatValue(numberValue))
+			applyOutputTemplate(&partial, metric, numberValue)
+
+			if !math.IsInf(numberValue, 0) && !math.IsNaN(numberValue) {
+				pd := generatePerfdata(perfdataMetric(metric), numberValue, metricWarn, metricCrit)
+				applyValueUnit(&pd)
+				partial.Perfdata.Add(&pd)
+			}
+
+			overall.AddSubcheck(partial)
+		}
+	}
+
+	series := make([]model.Metric, 0, len(groups))
+	for _, group := range groups {
+		series = append(series, group.Metric)
+	}
+
+	evaluateSeriesResults(overall, series, filtered)
+
+	if isStatsMode() {
+		overall.AddSubcheck(evaluateStats(&response))
+	}
+
+	return overall, warnings, nil
+}
+
+// parseQuantiles parses the quantiles of --histogram-quantile, without any the 0.95-quantile is evaluated
+func parseQuantiles() ([]*query.Quantile, error) {
+	if len(cliQueryConfig.HistogramQuantiles) == 0 {
+		return []*query.Quantile{{Quantile: 0.95}}, nil
+	}
+
+	quantiles := make([]*query.Quantile, 0, len(cliQueryConfig.HistogramQuantiles))
+
+	for _, spec := range cliQueryConfig.HistogramQuantiles {
+		q, err := query.ParseQuantile(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		quantiles = append(quantiles, q)
+	}
+
+	return quantiles, nil
+}
+
+// evaluateNamedQuery evaluates a named query and returns a PartialResult containing the
+// results of all series. The perfdata labels are prefixed with the name of the query.
+func evaluateNamedQuery(ctx context.Context, c *client.Client, q query.NamedQuery, overrides []*query.ThresholdOverride) goresult.PartialResult {
+	partial := goresult.NewPartialResult()
+
+	var annotations client.Annotations
+
+	overall, warnings, err := evaluateQuery(client.WithAnnotations(ctx, &annotations), c, q, overrides)
+	if err != nil {
+		_ = partial.SetState(check.Unknown)
+		partial.Output = fmt.Sprintf("%s - %s", q.Name, err.Error())
+
+		return partial
+	}
+
+	if cliQueryConfig.WarningsState != "" {
+		for _, annotation := range evaluateAnnotations(warnings, annotations.Infos()) {
+			overall.AddSubcheck(annotation)
+		}
+	}
+
+	partial.Output = fmt.Sprintf("%s - %s", q.Name, overall.GetSummary())
+
+	if cliQueryConfig.WarningsState == "" && len(warnings) != 0 {
+		partial.Output += fmt.Sprintf(" - HTTP Warnings: %v", strings.Join(warnings, ", "))
+	}
+
+	partial.PartialResults = overall.PartialResults
+	prefixPerfdata(partial.PartialResults, q.Name+"_")
+
+	return partial
+}
+
+// prefixPerfdata adds the prefix to the perfdata labels of all PartialResults
+func prefixPerfdata(partials []goresult.PartialResult, prefix string) {
+	for i := range partials {
+		for _, pd := range partials[i].Perfdata {
+			pd.Label = prefix + pd.Label
+		}
+
+		prefixPerfdata(partials[i].PartialResults, prefix)
+	}
+}
+
+// evaluateQuery performs a single query and evaluates the result against the thresholds of the query.
+// The returned Overall contains a PartialResult for each series of the result.
+func evaluateQuery(ctx context.Context, c *client.Client, q query.NamedQuery, overrides []*query.ThresholdOverride) (*goresult.Overall, v1.Warnings, error) {
+	var response, previousResponse client.Response
+
+	now := queryTime()
+
+	if cliQueryConfig.ForecastTarget != "" {
+		return forecastQuery(ctx, c, q, now, overrides)
+	}
+
+	if cliQueryConfig.DutyCycle != "" {
+		return dutyCycleQuery(ctx, c, q, now, overrides)
+	}
+
+	result, warnings, err := c.API.Query(client.WithResponse(ctx, &response), q.Expr, now, queryOptions()...)
+
+	if err != nil {
+		// The v1.API can't decode string results, so we do it ourselves
+		stringVal, errS := response.StringResult()
+		if errS != nil {
+			return nil, warnings, err
+		}
+
+		result = stringVal
+	}
+
+	if cliQueryConfig.CompareOffset != "" && result.Type() != model.ValVector {
+		return nil, warnings, fmt.Errorf("%s value results are not supported with --compare-offset, expected a vector", result.Type())
+	}
+
+	overall := &goresult.Overall{}
+
+	// Series returned by a vector or matrix result and the number of series removed by the label filters
+	var series []model.Metric
+
+	filtered := 0
+
+	switch result.Type() {
+	default:
+		return nil, warnings, errors.New("none value results are not supported")
+	case model.ValScalar:
+		// Scalar - a simple numeric floating point value, evaluated like a single series
+		scalarVal := result.(*model.Scalar)
+		numberValue := float64(scalarVal.Value)
+		partial := goresult.NewPartialResult()
+
+		_ = partial.SetState(evaluateThresholds(numberValue, q.Warning, q.Critical))
+
+		partial.Output = generateMetricOutput("scalar", formatValue(numberValue))
+		applyOutputTemplate(&partial, model.Metric{}, numberValue)
+
+		if !math.IsInf(numberValue, 0) && !math.IsNaN(numberValue) {
+			perf := generateValuePerfdata("scalar", numberValue, q.Warning, q.Critical)
+			applyValueUnit(&perf)
+			partial.Perfdata.Add(&perf)
+		}
+
+		overall.AddSubcheck(partial)
+	case model.ValNone:
+		return nil, warnings, errors.New("none value results are not supported")
+	case model.ValString:
+		// String - a simple string value, matched against the expected regular expression
+		if cliQueryConfig.ExpectString == "" {
+			return nil, warnings, errors.New("string value results require --expect-string")
+		}
+
+		overall.AddSubcheck(evaluateString(result.(*model.String)))
+	case model.ValVector:
+		// Instant vector - a set of time series containing a single sample for each time series, all sharing the same timestamp
+		vectorVal, vectorFiltered := filterVector(result.(model.Vector))
+		filtered = vectorFiltered
+
+		series = make([]model.Metric, 0, len(vectorVal))
+		for _, sample := range vectorVal {
+			series = append(series, sample.Metric)
+		}
+
+		// The samples of an instant vector carry the evaluation time instead of the time of the last sample
+		if cliQueryConfig.MaxAge != "" {
+			return nil, warnings, errors.New("--max-age requires a range vector result, e.g. up[5m]")
+		}
+
+		// Compare the series with their values at the given offset instead of evaluating the raw values
+		if cliQueryConfig.CompareOffset != "" {
+			compareWarnings, err := compareVector(client.WithResponse(ctx, &previousResponse), c, q, vectorVal, now, overrides, overall)
+			warnings = append(warnings, compareWarnings...)
+
+			if err != nil {
+				return nil, warnings, err
+			}
+
+			break
+		}
+
+		// Set initial capacity to reduce memory allocations
+		for _, sample := range vectorVal {
+			numberValue := float64(sample.Value)
+			partial := goresult.NewPartialResult()
+			sampleWarn, sampleCrit := query.SelectThresholds(overrides, sample.Metric, q.Warning, q.Critical)
+
+			// Native histograms have no float value, but count, sum and buckets
+			if sample.Histogram != nil {
+				partial = evaluateHistogram(sample.Metric, sample.Histogram, sample.Timestamp, q.Warning, q.Critical, overrides, now)
+				overall.AddSubcheck(partial)
+
+				continue
+			}
+
+			_ = partial.SetState(evaluateThresholds(numberValue, sampleWarn, sampleCrit))
+			evaluateStateLabel(&partial, sample.Metric)
+
+			// Format the metric and RC output for console output
+			partial.Output = generateMetricOutput(sample.Metric.String(), formatValue(numberValue))
+			applyOutputTemplate(&partial, sample.Metric, numberValue)
+
+			// Generate Perfdata from API return
+			if !math.IsInf(numberValue, 0) && !math.IsNaN(numberValue) {
+				perf := generateValuePerfdata(perfdataMetric(sample.Metric), numberValue, sampleWarn, sampleCrit)
+				applyValueUnit(&perf)
+				partial.Perfdata.Add(&perf)
+			}
+
+			overall.AddSubcheck(partial)
+		}
+
+	case model.ValMatrix:
+		// Range vector - a set of time series containing a range of data points over time for each time series -> Matrix
+		// An example query for a matrix 'go_goroutines{job="prometheus"}[5m]'
+
+		// Note: Without an aggregation only the latest value will be evaluated, other values will be ignored!
+		matrixVal, matrixFiltered := filterMatrix(result.(model.Matrix))
+		filtered = matrixFiltered
+		series = matrixMetrics(matrixVal)
+
+		for _, samplestream := range matrixVal {
+			streamWarn, streamCrit := query.SelectThresholds(overrides, samplestream.Metric, q.Warning, q.Critical)
+
+			if cliQueryConfig.Aggregate != "" {
+				partial := aggregateSampleStream(samplestream, streamWarn, streamCrit, now)
+				overall.AddSubcheck(partial)
+
+				continue
+			}
+
+			// Series of native histograms only contain histogram samples
+			if len(samplestream.Values) == 0 && len(samplestream.Histograms) > 0 {
+				histogrampair := samplestream.Histograms[len(samplestream.Histograms)-1]
+				partial := evaluateHistogram(samplestream.Metric, histogrampair.Histogram, histogrampair.Timestamp, q.Warning, q.Critical, overrides, now)
+				overall.AddSubcheck(partial)
+
+				continue
+			}
+
+			samplepair := samplestream.Values[len(samplestream.Values)-1]
+
+			numberValue := float64(samplepair.Value)
+
+			partial := goresult.NewPartialResult()
+
+			_ = partial.SetState(evaluateThresholds(numberValue, streamWarn, streamCrit))
+			evaluateStateLabel(&partial, samplestream.Metric)
+
+			// Format the metric and RC output for console output
+			partial.Output = generateMetricOutput(samplepair.String(), formatValue(numberValue))
+
+			valueString := samplepair.Value.String()
+
+			valueNumber, err := strconv.ParseFloat(valueString, 64)
+			if err == nil {
+				pd := generateValuePerfdata(perfdataMetric(samplestream.Metric), valueNumber, streamWarn, streamCrit)
+				applyValueUnit(&pd)
+
+				// Generate Perfdata from API return
+				if !math.IsInf(numberValue, 0) && !math.IsNaN(numberValue) {
+					partial.Perfdata.Add(&pd)
+				}
+			}
+
+			if cliQueryConfig.MaxAge != "" {
+				evaluateAge(&partial, samplestream.Metric, samplepair.Timestamp, now)
+			}
+
+			applyOutputTemplate(&partial, samplestream.Metric, numberValue)
+
+			overall.AddSubcheck(partial)
+		}
+	}
+
+	if result.Type() == model.ValVector || result.Type() == model.ValMatrix {
+		evaluateSeriesResults(overall, series, filtered)
+	}
+
+	if isStatsMode() {
+		if cliQueryConfig.CompareOffset != "" {
+			// The statistics of the query at the offset are summed up
+			overall.AddSubcheck(evaluateStats(&response, &previousResponse))
+		} else {
+			overall.AddSubcheck(evaluateStats(&response))
+		}
+	}
+
+	return overall, warnings, nil
+}
+
+// queryTime returns the evaluation time of the queries, set with --time
+func queryTime() time.Time {
+	// We already make sure it's valid
+	t, _ := query.ParseTime(cliQueryConfig.Time, time.Now())
+
+	return t
+}
+
+// queryOptions returns the options of the query API call
+func queryOptions() []v1.Option {
+	var opts []v1.Option
+
+	// We already make sure these are valid
+	if cliQueryConfig.QueryTimeout != "" {
+		timeout, _ := model.ParseDuration(cliQueryConfig.QueryTimeout)
+		opts = append(opts, v1.WithTimeout(time.Duration(timeout)))
+	}
+
+	if cliQueryConfig.LookbackDelta != "" {
+		lookbackDelta, _ := model.ParseDuration(cliQueryConfig.LookbackDelta)
+		opts = append(opts, v1.WithLookbackDelta(time.Duration(lookbackDelta)))
+	}
+
+	if isStatsMode() {
+		opts = append(opts, v1.WithStats(v1.AllStatsValue))
+	}
+
+	return opts
+}
+
+// isStatsMode returns true if the statistics of the query are requested
+func isStatsMode() bool {
+	return cliQueryConfig.Stats ||
+		cliQueryConfig.WarningEvalTime != "" || cliQueryConfig.CriticalEvalTime != "" ||
+		cliQueryConfig.WarningQueueTime != "" || cliQueryConfig.CriticalQueueTime != "" ||
+		cliQueryConfig.WarningSamples != "" || cliQueryConfig.CriticalSamples != ""
+}
+
+// evaluateStats evaluates the evaluation time, queue time and total samples of a query
+// against their thresholds and adds them as perfdata.
+// The statistics of several queries, e.g. numerator and denominator, are summed up.
+func evaluateStats(responses ...*client.Response) goresult.PartialResult {
+	partial := goresult.NewPartialResult()
+
+	stats := &client.QueryStats{}
+
+	for _, response := range responses {
+		s, err := response.Stats()
+		if err != nil {
+			_ = partial.SetState(check.Unknown)
+			partial.Output = "query stats - " + err.Error()
+
+			return partial
+		}
+
+		stats.Timings.EvalTotalTime += s.Timings.EvalTotalTime
+		stats.Timings.ExecQueueTime += s.Timings.ExecQueueTime
+		stats.Samples.TotalQueryableSamples += s.Samples.TotalQueryableSamples
+	}
+
+	// We already make sure these are valid
+	warnEvalTime, _ := parseOptionalThreshold(cliQueryConfig.WarningEvalTime)
+	critEvalTime, _ := parseOptionalThreshold(cliQueryConfig.CriticalEvalTime)
+	warnQueueTime, _ := parseOptionalThreshold(cliQueryConfig.WarningQueueTime)
+	critQueueTime, _ := parseOptionalThreshold(cliQueryConfig.CriticalQueueTime)
+	warnSamples, _ := parseOptionalThreshold(cliQueryConfig.WarningSamples)
+	critSamples, _ := parseOptionalThreshold(cliQueryConfig.CriticalSamples)
+
+	states := []int{check.OK}
+
+	for _, v := range []struct {
+		value    float64
+		warning  *check.Threshold
+		critical *check.Threshold
+	}{
+		{stats.Timings.EvalTotalTime, warnEvalTime, critEvalTime},
+		{stats.Timings.ExecQueueTime, warnQueueTime, critQueueTime},
+		{float64(stats.Samples.TotalQueryableSamples), warnSamples, critSamples},
+	} {
+		if v.critical != nil && v.critical.DoesViolate(v.value) {
+			states = append(states, check.Critical)
+		} else if v.warning != nil && v.warning.DoesViolate(v.value) {
+			states = append(states, check.Warning)
+		}
+	}
+
+	_ = partial.SetState(goresult.WorstState(states...))
+	partial.Output = fmt.Sprintf("query stats - eval time: %ss, queue time: %ss, samples: %d",
+		check.FormatFloat(stats.Timings.EvalTotalTime),
+		check.FormatFloat(stats.Timings.ExecQueueTime),
+		stats.Samples.TotalQueryableSamples)
+
+	partial.Perfdata.Add(&perfdata.Perfdata{Label: "query_eval_time", Value: stats.Timings.EvalTotalTime, Uom: "s", Warn: warnEvalTime, Crit: critEvalTime})
+	partial.Perfdata.Add(&perfdata.Perfdata{Label: "query_queue_time", Value: stats.Timings.ExecQueueTime, Uom: "s", Warn: warnQueueTime, Crit: critQueueTime})
+	partial.Perfdata.Add(&perfdata.Perfdata{Label: "query_samples", Value: stats.Samples.TotalQueryableSamples, Warn: warnSamples, Crit: critSamples})
+
+	return partial
+}
+
+// evaluateStateLabel overrides the state of a series with the state mapped from its --state-label.
+// Series without the label keep the state of the thresholds.
+func evaluateStateLabel(partial *goresult.PartialResult, metric model.Metric) {
+	value, ok := metric[model.LabelName(cliQueryConfig.StateLabel)]
+	if cliQueryConfig.StateLabel == "" || !ok {
+		return
+	}
+
+	// We already make sure it's valid
+	state, _ := convertStateToInt(cliQueryConfig.UnmappedState)
+
+	if mapped, ok := cliQueryConfig.stateLabelMap.Lookup(string(value)); ok {
+		state = mapped
+	}
+
+	_ = partial.SetState(state)
+}
+
+// evaluateAge adds the age of the sample to the PartialResult and raises the state
+// to the configured state if the sample is older than --max-age
+func evaluateAge(partial *goresult.PartialResult, metric model.Metric, timestamp model.Time, now time.Time) {
+	// We already make sure these are valid
+	maxAge, _ := model.ParseDuration(cliQueryConfig.MaxAge)
+	staleState, _ := convertStateToInt(cliQueryConfig.StaleState)
+
+	age := now.Sub(timestamp.Time())
+
+	if age > time.Duration(maxAge) {
+		_ = partial.SetState(goresult.WorstState(partial.GetStatus(), staleState))
+		partial.Output += fmt.Sprintf(" - stale: last sample %s ago", model.Duration(age.Round(time.Second)))
+	}
+
+	pd := generatePerfdata(perfdataMetric(metric)+"_age", math.Max(0, age.Seconds()), nil, nil)
+	pd.Uom = "s"
+	partial.Perfdata.Add(&pd)
+}
+
+// activeModes returns the flags of the evaluation modes that are set, these modes can't be combined.
+// The value map only applies to the raw values of a query, not to the values derived by the other modes.
+func activeModes() []string {
+	var modes []string
+
+	for _, mode := range []struct {
+		flag   string
+		active bool
+	}{
+		{"numerator", cliQueryConfig.Numerator != ""},
+		{"histogram", cliQueryConfig.Histogram != ""},
+		{"compare-offset", cliQueryConfig.CompareOffset != ""},
+		{"forecast-target", cliQueryConfig.ForecastTarget != ""},
+		{"duty-cycle", cliQueryConfig.DutyCycle != ""},
+		{"aggregate", cliQueryConfig.Aggregate != ""},
+		{"value-map", cliQueryConfig.ValueMap != ""},
+		{"expect-string", cliQueryConfig.ExpectString != ""},
+	} {
+		if mode.active {
+			modes = append(modes, mode.flag)
+		}
+	}
+
+	return modes
+}
+
+// isViolationCountMode returns true if the overall state is derived from the number of violating series
+func isViolationCountMode() bool {
+	return cliQueryConfig.WarningCount != "" || cliQueryConfig.CriticalCount != "" ||
+		cliQueryConfig.WarningPercent != "" || cliQueryConfig.CriticalPercent != ""
+}
+
+// parseOptionalThreshold parses a threshold and returns nil if no threshold is given
+func parseOptionalThreshold(spec string) (t *check.Threshold, err error) {
+	if spec != "" {
+		t, err = check.ParseThreshold(spec)
+	}
+
+	return t, err
+}
+
+// evaluateViolations counts the series violating their thresholds and evaluates the number
+// and share of these series against the count and percent thresholds.
+// The series are added below the returned PartialResult, so that their states
+// don't influence the overall state.
+func evaluateViolations(series []goresult.PartialResult) goresult.PartialResult {
+	// We already make sure these are valid
+	warnCount, _ := parseOptionalThreshold(cliQueryConfig.WarningCount)
+	critCount, _ := parseOptionalThreshold(cliQueryConfig.CriticalCount)
+	warnPercent, _ := parseOptionalThreshold(cliQueryConfig.WarningPercent)
+	critPercent, _ := parseOptionalThreshold(cliQueryConfig.CriticalPercent)
+
+	var violations int
+
+	for i := range series {
+		state := series[i].GetStatus()
+		if state == check.Warning || state == check.Critical {
+			violations++
+		}
+	}
+
+	var share float64
+	if len(series) > 0 {
+		share = float64(violations) / float64(len(series)) * 100
+	}
+
+	states := []int{check.OK}
+
+	for _, v := range []struct {
+		value    float64
+		warning  *check.Threshold
+		critical *check.Threshold
+	}{
+		{float64(violations), warnCount, critCount},
+		{share, warnPercent, critPercent},
+	} {
+		if v.critical != nil && v.critical.DoesViolate(v.value) {
+			states = append(states, check.Critical)
+		} else if v.warning != nil && v.warning.DoesViolate(v.value) {
+			states = append(states, check.Warning)
+		}
+	}
+
+	partial := goresult.NewPartialResult()
+	_ = partial.SetState(goresult.WorstState(states...))
+	partial.Output = fmt.Sprintf("%d of %d series violate the thresholds (%s%%)", violations, len(series), check.FormatFloat(share))
+	partial.PartialResults = series
+
+	partial.Perfdata.Add(&perfdata.Perfdata{Label: "violations", Value: violations, Warn: warnCount, Crit: critCount})
+	partial.Perfdata.Add(&perfdata.Perfdata{Label: "violations_percent", Value: share, Uom: "%", Warn: warnPercent, Crit: critPercent, Min: 0, Max: 100})
+
+	return partial
+}
+
+// filterVector removes the series of a vector that are excluded by the label filters
+// and returns the number of removed series
+func filterVector(vectorVal model.Vector) (model.Vector, int) {
+	kept := make(model.Vector, 0, len(vectorVal))
+
+	for _, sample := range vectorVal {
+		if cliQueryConfig.filter.Keep(sample.Metric) {
+			kept = append(kept, sample)
+		}
+	}
+
+	return kept, len(vectorVal) - len(kept)
+}
+
+// filterMatrix removes the series of a matrix that are excluded by the label filters
+// and returns the number of removed series
+func filterMatrix(matrixVal model.Matrix) (model.Matrix, int) {
+	kept := make(model.Matrix, 0, len(matrixVal))
+
+	for _, samplestream := range matrixVal {
+		if cliQueryConfig.filter.Keep(samplestream.Metric) {
+			kept = append(kept, samplestream)
+		}
+	}
+
+	return kept, len(matrixVal) - len(kept)
+}
+
+// matrixMetrics returns the metrics of all series of a matrix
+func matrixMetrics(matrixVal model.Matrix) []model.Metric {
+	series := make([]model.Metric, 0, len(matrixVal))
+	for _, samplestream := range matrixVal {
+		series = append(series, samplestream.Metric)
+	}
+
+	return series
+}
+
+// evaluateSeriesResults applies the checks on the series of a vector or matrix result:
+// the number of violating series, empty results, the series count and the expected series.
+// The number of series removed by the label filters is added to the summary.
+func evaluateSeriesResults(overall *goresult.Overall, series []model.Metric, filtered int) {
+	seriesCount := len(series)
+
+	if isViolationCountMode() {
+		overall.PartialResults = []goresult.PartialResult{evaluateViolations(overall.PartialResults)}
+	}
+
+	// When the query returned no series we add a PartialResult with the configured state and message
+	if seriesCount == 0 {
+		sc := goresult.NewPartialResult()
+		// We already make sure it's valid
+		emptyState, _ := convertStateToInt(cliQueryConfig.EmptyState)
+		_ = sc.SetState(emptyState)
+		sc.Output = cliQueryConfig.EmptyMessage
+		overall.AddSubcheck(sc)
+	}
+
+	if cliQueryConfig.MinSeries > 0 || cliQueryConfig.MaxSeries >= 0 {
+		overall.AddSubcheck(evaluateSeriesCount(seriesCount))
+	}
+
+	if len(cliQueryConfig.ExpectSeries) > 0 || cliQueryConfig.ExpectSeriesFile != "" {
+		evaluateExpectedSeries(overall, series)
+	}
+
+	if filtered > 0 {
+		overall.Summary = fmt.Sprintf("%s - filtered series: %d", overall.GetSummary(), filtered)
+	}
+}
+
+// loadExpectedSeries returns the expected series of --expect-series and --expect-series-file
+func loadExpectedSeries() ([]model.LabelSet, error) {
+	expected := make([]model.LabelSet, 0, len(cliQueryConfig.ExpectSeries))
+
+	for _, spec := range cliQueryConfig.ExpectSeries {
+		labels, err := query.ParseExpectedSeries(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		expected = append(expected, labels)
+	}
+
+	if cliQueryConfig.ExpectSeriesFile == "" {
+		return expected, nil
+	}
+
+	f, err := os.Open(cliQueryConfig.ExpectSeriesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	fromFile, err := query.ReadExpectedSeries(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", cliQueryConfig.ExpectSeriesFile, err)
+	}
+
+	return append(expected, fromFile...), nil
+}
+
+// evaluateExpectedSeries adds a PartialResult for each expected series missing in the result
+// and, if enabled, for each series that was not expected
+func evaluateExpectedSeries(overall *goresult.Overall, series []model.Metric) {
+	// We already make sure these are valid
+	missingState, _ := convertStateToInt(cliQueryConfig.MissingState)
+	unexpectedState, _ := convertStateToInt(cliQueryConfig.UnexpectedState)
+
+	missing, unexpected := query.FindMissingSeries(cliQueryConfig.expectedSeries, series)
+
+	for _, labels := range missing {
+		partial := goresult.NewPartialResult()
+		_ = partial.SetState(missingState)
+		partial.Output = fmt.Sprintf(" %s - expected series is missing", labels)
+		overall.AddSubcheck(partial)
+	}
+
+	if !cliQueryConfig.ReportUnexpected {
+		return
+	}
+
+	for _, metric := range unexpected {
+		partial := goresult.NewPartialResult()
+		_ = partial.SetState(unexpectedState)
+		partial.Output = fmt.Sprintf(" %s - series is not expected", metric)
+		overall.AddSubcheck(partial)
+	}
 }
 
-type User struct {
-	Name       string
-	Occupation string
+// forecastQuery performs a range query and estimates for each series the time until it crosses
+// the forecast target. The thresholds are applied to the remaining time in seconds.
+func forecastQuery(ctx context.Context, c *client.Client, q query.NamedQuery, now time.Time, overrides []*query.ThresholdOverride) (*goresult.Overall, v1.Warnings, error) {
+	// We already make sure these are valid
+	target, _ := strconv.ParseFloat(cliQueryConfig.ForecastTarget, 64)
+	lookback, _ := model.ParseDuration(cliQueryConfig.ForecastRange)
+	step, _ := model.ParseDuration(cliQueryConfig.ForecastStep)
+
+	r := v1.Range{
+		Start: now.Add(-time.Duration(lookback)),
+		End:   now,
+		Step:  time.Duration(step),
+	}
+
+	var response client.Response
+
+	result, warnings, err := c.API.QueryRange(client.WithResponse(ctx, &response), q.Expr, r, queryOptions()...)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	matrixVal, ok := result.(model.Matrix)
+	if !ok {
+		return nil, warnings, fmt.Errorf("%s value results are not supported with --forecast-target", result.Type())
+	}
+
+	matrixVal, filtered := filterMatrix(matrixVal)
+
+	overall := &goresult.Overall{}
+
+	for _, samplestream := range matrixVal {
+		partial := goresult.NewPartialResult()
+		label := samplestream.Metric.String()
+		streamWarn, streamCrit := query.SelectThresholds(overrides, samplestream.Metric, q.Warning, q.Critical)
+
+		remaining, err := query.TimeToTarget(samplestream.Values, now, target, cliQueryConfig.ForecastDirection)
+		if err != nil {
+			_ = partial.SetState(check.Unknown)
+			partial.Output = fmt.Sprintf(" %s - %s", label, err.Error())
+			overall.AddSubcheck(partial)
+
+			continue
+		}
+
+		_ = partial.SetState(evaluateThresholds(remaining, streamWarn, streamCrit))
+
+		last := samplestream.Values[len(samplestream.Values)-1].Value
+
+		if math.IsInf(remaining, 1) {
+			partial.Output = fmt.Sprintf("%s - will not reach %s", generateMetricOutput(label, formatValue(float64(last))), cliQueryConfig.ForecastTarget)
+		} else {
+			remainingDuration := time.Duration(remaining * float64(time.Second)).Round(time.Second)
+
+			partial.Output = fmt.Sprintf("%s - reaches %s in %s at %s",
+				generateMetricOutput(label, formatValue(float64(last))),
+				cliQueryConfig.ForecastTarget,
+				model.Duration(remainingDuration),
+				now.Add(remainingDuration).UTC().Format(time.RFC3339))
+
+			pd := generatePerfdata(perfdataMetric(samplestream.Metric)+"_time_to_target", remaining, streamWarn, streamCrit)
+			pd.Uom = "s"
+			partial.Perfdata.Add(&pd)
+		}
+
+		applyOutputTemplate(&partial, samplestream.Metric, remaining)
+
+		overall.AddSubcheck(partial)
+	}
+
+	evaluateSeriesResults(overall, matrixMetrics(matrixVal), filtered)
+
+	if isStatsMode() {
+		overall.AddSubcheck(evaluateStats(&response))
+	}
+
+	return overall, warnings, nil
 }
 
-var cliQueryConfig QueryConfig
+// dutyCycleQuery performs a range query over the window of --duty-cycle and calculates for each series
+// the share of samples that violate the warning and critical thresholds.
+// These shares are evaluated against --warning-duty-cycle and --critical-duty-cycle.
+func dutyCycleQuery(ctx context.Context, c *client.Client, q query.NamedQuery, now time.Time, overrides []*query.ThresholdOverride) (*goresult.Overall, v1.Warnings, error) {
+	// We already make sure these are valid
+	window, _ := model.ParseDuration(cliQueryConfig.DutyCycle)
+	step, _ := model.ParseDuration(cliQueryConfig.DutyCycleStep)
+	warnDutyCycle, _ := parseOptionalThreshold(cliQueryConfig.WarningDutyCycle)
+	critDutyCycle, _ := parseOptionalThreshold(cliQueryConfig.CriticalDutyCycle)
 
-var replacer = strings.NewReplacer("{", "_", "}", "", "\"", "", ",", "_", " ", "")
+	r := v1.Range{
+		Start: now.Add(-time.Duration(window)),
+		End:   now,
+		Step:  time.Duration(step),
+	}
 
-func generateMetricOutput(metric string, value string) string {
-	// Format the metric and RC output for console output
-	return fmt.Sprintf(" %s - value: %s", metric, value)
+	var response client.Response
+
+	result, warnings, err := c.API.QueryRange(client.WithResponse(ctx, &response), q.Expr, r, queryOptions()...)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	matrixVal, ok := result.(model.Matrix)
+	if !ok {
+		return nil, warnings, fmt.Errorf("%s value results are not supported with --duty-cycle", result.Type())
+	}
+
+	matrixVal, filtered := filterMatrix(matrixVal)
+
+	overall := &goresult.Overall{}
+
+	for _, samplestream := range matrixVal {
+		partial := goresult.NewPartialResult()
+		label := samplestream.Metric.String()
+		streamWarn, streamCrit := query.SelectThresholds(overrides, samplestream.Metric, q.Warning, q.Critical)
+
+		warnShare, err := query.DutyCycle(samplestream.Values, streamWarn)
+		if err != nil {
+			_ = partial.SetState(check.Unknown)
+			partial.Output = fmt.Sprintf(" %s - %s", label, err.Error())
+			overall.AddSubcheck(partial)
+
+			continue
+		}
+
+		critShare, _ := query.DutyCycle(samplestream.Values, streamCrit)
+
+		switch {
+		case critDutyCycle != nil && critDutyCycle.DoesViolate(critShare):
+			_ = partial.SetState(check.Critical)
+		case warnDutyCycle != nil && warnDutyCycle.DoesViolate(warnShare):
+			_ = partial.SetState(check.Warning)
+		default:
+			_ = partial.SetState(check.OK)
+		}
+
+		partial.Output = fmt.Sprintf(" %s - %s%% of %d samples over warning, %s%% over critical",
+			label, check.FormatFloat(warnShare), len(samplestream.Values), check.FormatFloat(critShare))
+
+		for _, pd := range []perfdata.Perfdata{
+			generatePerfdata(perfdataMetric(samplestream.Metric)+"_warning_duty_cycle", warnShare, warnDutyCycle, nil),
+			generatePerfdata(perfdataMetric(samplestream.Metric)+"_critical_duty_cycle", critShare, nil, critDutyCycle),
+		} {
+			pd.Uom = "%"
+			pd.Min = 0
+			pd.Max = 100
+			partial.Perfdata.Add(&pd)
+		}
+
+		overall.AddSubcheck(partial)
+	}
+
+	evaluateSeriesResults(overall, matrixMetrics(matrixVal), filtered)
+
+	if isStatsMode() {
+		overall.AddSubcheck(evaluateStats(&response))
+	}
+
+	return overall, warnings, nil
+}
+
+// perfdataMetric returns the name of a series used in perfdata labels,
+// generated with the template of --perfdata-label if set
+func perfdataMetric(metric model.Metric) string {
+	if cliQueryConfig.perfdataLabel == nil {
+		return metric.String()
+	}
+
+	labels := make(map[string]string, len(metric))
+	for name, value := range metric {
+		labels[string(name)] = string(value)
+	}
+
+	var sb strings.Builder
+	if err := cliQueryConfig.perfdataLabel.Execute(&sb, labels); err != nil {
+		return metric.String()
+	}
+
+	return sb.String()
+}
+
+// applyValueUnit sets the unit and the limits of --uom, --min and --max on the Perfdata of a value
+func applyValueUnit(pd *perfdata.Perfdata) {
+	pd.Uom = cliQueryConfig.Uom
+
+	// We already make sure these are valid
+	if cliQueryConfig.Min != "" {
+		pd.Min, _ = strconv.ParseFloat(cliQueryConfig.Min, 64)
+	}
+
+	if cliQueryConfig.Max != "" {
+		pd.Max, _ = strconv.ParseFloat(cliQueryConfig.Max, 64)
+	}
 }
 
 type Number interface {
@@ -51,139 +1657,379 @@ func generatePerfdata[T Number](metric string, value T, warning, critical *check
 	}
 }
 
+// generateValuePerfdata generates the Perfdata of a raw value of the query,
+// the thresholds are not evaluated when using a value map
+func generateValuePerfdata(metric string, value float64, warning, critical *check.Threshold) perfdata.Perfdata {
+	if cliQueryConfig.valueMap != nil {
+		warning, critical = nil, nil
+	}
+
+	return generatePerfdata(metric, value, warning, critical)
+}
+
 var queryCmd = &cobra.Command{
 	Use:   "query",
 	Short: "Checks the status of a Prometheus query",
 	Long: `Checks the status of a Prometheus query and evaluates the result of the alert.
-Note: Time range values e.G. 'go_memstats_alloc_bytes_total[0s]' only the latest value will be evaluated, other values will be ignored!`,
+Note: For time range values e.G. 'go_memstats_alloc_bytes_total[5m]' only the latest value will be evaluated,
+unless an aggregation function is set with --aggregate.`,
 	Example: `
 	$ check_prometheus query -q 'go_gc_duration_seconds_count' -c 5000 -w 2000
 	CRITICAL - 2 Metrics: 1 Critical - 0 Warning - 1 Ok
 	 \_[OK] go_gc_duration_seconds_count{instance="localhost:9090", job="prometheus"} - value: 1599
 	 \_[CRITICAL] go_gc_duration_seconds_count{instance="node-exporter:9100", job="node-exporter"} - value: 79610
-	 | value_go_gc_duration_seconds_count_localhost:9090_prometheus=1599 value_go_gc_duration_seconds_count_node-exporter:9100_node-exporter=79610`,
-	PreRun: func(_ *cobra.Command, _ []string) {
-		if cliQueryConfig.Warning == "" || cliQueryConfig.Critical == "" {
-			check.ExitError(errors.New("please specify warning and critical thresholds"))
+	 | value_go_gc_duration_seconds_count_localhost:9090_prometheus=1599 value_go_gc_duration_seconds_count_node-exporter:9100_node-exporter=79610
+
+	$ check_prometheus query -q 'go_goroutines{job="prometheus"}[5m]' --aggregate avg -c 50 -w 40
+	[OK] - states: ok=1
+	\_ [OK]  go_goroutines{instance="localhost:9090", job="prometheus"} - avg: 37.4
+	|go_goroutines_instance_localhost:9090_job_prometheus_avg=37.4;40;50`,
+	PreRun: func(cmd *cobra.Command, _ []string) {
+		if cliQueryConfig.QueryName != "" {
+			if len(cliQueryConfig.Queries) > 0 {
+				check.ExitError(errors.New("--query-name can't be combined with --query"))
+			}
+
+			if err := applyLibraryQuery(cmd); err != nil {
+				check.ExitError(err)
+			}
 		}
-	},
-	Run: func(_ *cobra.Command, _ []string) {
-		crit, err := check.ParseThreshold(cliQueryConfig.Critical)
+
+		if len(cliQueryConfig.Queries) == 0 && cliQueryConfig.Numerator == "" && cliQueryConfig.Histogram == "" {
+			check.ExitError(errors.New(`required flag(s) "query" not set`))
+		}
+
+		if modes := activeModes(); len(modes) > 1 {
+			check.ExitError(fmt.Errorf("--%s can't be combined with --%s", modes[0], strings.Join(modes[1:], ", --")))
+		}
+
+		if cliQueryConfig.Histogram != "" {
+			if len(cliQueryConfig.Queries) > 0 {
+				check.ExitError(errors.New("--histogram can't be combined with --query"))
+			}
+
+			if _, err := model.ParseDuration(cliQueryConfig.HistogramWindow); err != nil {
+				check.ExitError(fmt.Errorf("invalid value for --histogram-window: %s", cliQueryConfig.HistogramWindow))
+			}
+		}
+
+		if cliQueryConfig.Numerator != "" || cliQueryConfig.Denominator != "" {
+			if cliQueryConfig.Numerator == "" || cliQueryConfig.Denominator == "" {
+				check.ExitError(errors.New("please specify both --numerator and --denominator"))
+			}
+
+			if len(cliQueryConfig.Queries) > 0 {
+				check.ExitError(errors.New("--query can't be combined with --numerator and --denominator"))
+			}
+		}
+
+		if cliQueryConfig.ValueMap != "" {
+			valueMap, err := query.ParseValueMap(cliQueryConfig.ValueMap, convertStateToInt)
+			if err != nil {
+				check.ExitError(err)
+			}
+
+			cliQueryConfig.valueMap = valueMap
+		}
+
+		stateLabelMap, err := query.ParseStateLabelMap(cliQueryConfig.StateLabelMap, convertStateToInt)
 		if err != nil {
 			check.ExitError(err)
 		}
 
-		warn, err := check.ParseThreshold(cliQueryConfig.Warning)
+		cliQueryConfig.stateLabelMap = stateLabelMap
+
+		if _, err := query.ParseTime(cliQueryConfig.Time, time.Now()); err != nil {
+			check.ExitError(fmt.Errorf("invalid value for --time: %w", err))
+		}
+
+		for name, spec := range map[string]string{
+			"query-timeout":  cliQueryConfig.QueryTimeout,
+			"lookback-delta": cliQueryConfig.LookbackDelta,
+		} {
+			if spec == "" {
+				continue
+			}
+
+			if d, err := model.ParseDuration(spec); err != nil || d == 0 {
+				check.ExitError(fmt.Errorf("invalid value for --%s: %s", name, spec))
+			}
+		}
+
+		if cliQueryConfig.WarningsState != "" {
+			if _, err := convertStateToInt(cliQueryConfig.WarningsState); err != nil {
+				check.ExitError(fmt.Errorf("invalid value for --warnings-state: %s", cliQueryConfig.WarningsState))
+			}
+		}
+
+		if _, err := convertStateToInt(cliQueryConfig.UnmappedState); err != nil {
+			check.ExitError(fmt.Errorf("invalid value for --unmapped-state: %s", cliQueryConfig.UnmappedState))
+		}
+
+		if cliQueryConfig.Humanize != "" {
+			if err := output.ValidateMode(cliQueryConfig.Humanize); err != nil {
+				check.ExitError(err)
+			}
+		}
+
+		if cliQueryConfig.OutputTemplate != "" {
+			tmpl, err := output.NewTemplate("output", cliQueryConfig.OutputTemplate)
+			if err != nil {
+				check.ExitError(fmt.Errorf("invalid value for --output-template: %w", err))
+			}
+
+			cliQueryConfig.outputTemplate = tmpl
+		}
+
+		if cliQueryConfig.PerfdataLabel != "" {
+			// Missing labels are replaced by an empty string
+			tmpl, err := template.New("perfdata-label").Option("missingkey=zero").Parse(cliQueryConfig.PerfdataLabel)
+			if err != nil {
+				check.ExitError(fmt.Errorf("invalid value for --perfdata-label: %w", err))
+			}
+
+			cliQueryConfig.perfdataLabel = tmpl
+		}
+
+		for name, value := range map[string]string{"min": cliQueryConfig.Min, "max": cliQueryConfig.Max} {
+			if _, err := strconv.ParseFloat(value, 64); value != "" && err != nil {
+				check.ExitError(fmt.Errorf("invalid value for --%s: %s", name, value))
+			}
+		}
+
+		filter, err := query.ParseLabelFilter(cliQueryConfig.IncludeLabels, cliQueryConfig.ExcludeLabels)
 		if err != nil {
 			check.ExitError(err)
 		}
 
-		c := cliConfig.NewClient()
+		cliQueryConfig.filter = filter
 
-		err = c.Connect()
+		expectedSeries, err := loadExpectedSeries()
 		if err != nil {
-			check.ExitError(err)
+			check.ExitError(fmt.Errorf("invalid expected series: %w", err))
 		}
 
-		ctx, cancel := cliConfig.timeoutContext()
-		defer cancel()
+		cliQueryConfig.expectedSeries = expectedSeries
+
+		if _, err := convertStateToInt(cliQueryConfig.MissingState); err != nil {
+			check.ExitError(fmt.Errorf("invalid value for --missing-series-state: %s", cliQueryConfig.MissingState))
+		}
 
-		result, warnings, err := c.API.Query(ctx, cliQueryConfig.RawQuery, time.Now())
+		if _, err := convertStateToInt(cliQueryConfig.UnexpectedState); err != nil {
+			check.ExitError(fmt.Errorf("invalid value for --unexpected-series-state: %s", cliQueryConfig.UnexpectedState))
+		}
 
-		if err != nil {
-			if strings.Contains(err.Error(), "unmarshalerDecoder: unexpected value type \"string\"") {
-				err = errors.New("string value results are not supported")
+		if _, err := convertStateToInt(cliQueryConfig.ZeroDenominator); err != nil {
+			check.ExitError(fmt.Errorf("invalid value for --zero-denominator-state: %s", cliQueryConfig.ZeroDenominator))
+		}
+
+		if cliQueryConfig.Warning == "" || cliQueryConfig.Critical == "" {
+			check.ExitError(errors.New("please specify warning and critical thresholds"))
+		}
+
+		if cliQueryConfig.Aggregate != "" {
+			if err := query.ValidateAggregation(cliQueryConfig.Aggregate); err != nil {
+				check.ExitError(err)
 			}
+		}
+
+		if cliQueryConfig.Percentile < 0 || cliQueryConfig.Percentile > 100 {
+			check.ExitError(errors.New("percentile must be between 0 and 100"))
+		}
 
+		if err := query.ValidateHistogramView(cliQueryConfig.HistogramView); err != nil {
 			check.ExitError(err)
 		}
 
-		overall := goresult.Overall{}
+		quantiles, err := parseQuantiles()
+		if err != nil {
+			check.ExitError(err)
+		}
 
-		switch result.Type() {
-		default:
-			check.ExitError(errors.New("none value results are not supported"))
-		// Scalar - a simple numeric floating point value
-		case model.ValScalar:
-			check.ExitError(errors.New("scalar value results are not supported"))
-		case model.ValNone:
-			check.ExitError(errors.New("none value results are not supported"))
-		case model.ValString:
-			// String - a simple string value; currently unused
-			check.ExitError(errors.New("string value results are not supported"))
-		case model.ValVector:
-			// Instant vector - a set of time series containing a single sample for each time series, all sharing the same timestamp
-			vectorVal := result.(model.Vector)
-			// Set initial capacity to reduce memory allocations
-			for _, sample := range vectorVal {
-				numberValue := float64(sample.Value)
-				partial := goresult.NewPartialResult()
-
-				if crit.DoesViolate(numberValue) {
-					_ = partial.SetState(check.Critical)
-				} else if warn.DoesViolate(numberValue) {
-					_ = partial.SetState(check.Warning)
-				} else {
-					_ = partial.SetState(check.OK)
-				}
+		cliQueryConfig.quantiles = quantiles
 
-				// Format the metric and RC output for console output
-				partial.Output = generateMetricOutput(sample.Metric.String(), sample.Value.String())
+		if _, err := regexp.Compile(cliQueryConfig.ExpectString); err != nil {
+			check.ExitRaw(check.Unknown, "Invalid regular expression provided:", err.Error())
+		}
 
-				// Generate Perfdata from API return
-				if math.IsInf(numberValue, 0) || math.IsNaN(numberValue) {
-					continue
-				}
+		if _, err := convertStateToInt(cliQueryConfig.StringMatchState); err != nil {
+			check.ExitError(fmt.Errorf("invalid value for --string-match-state: %s", cliQueryConfig.StringMatchState))
+		}
 
-				perf := generatePerfdata(sample.Metric.String(), numberValue, warn, crit)
-				partial.Perfdata.Add(&perf)
-				overall.AddSubcheck(partial)
+		if _, err := convertStateToInt(cliQueryConfig.StringMismatchState); err != nil {
+			check.ExitError(fmt.Errorf("invalid value for --string-mismatch-state: %s", cliQueryConfig.StringMismatchState))
+		}
+
+		if _, err := convertStateToInt(cliQueryConfig.EmptyState); err != nil {
+			check.ExitError(fmt.Errorf("invalid value for --empty-state: %s", cliQueryConfig.EmptyState))
+		}
+
+		if _, err := convertStateToInt(cliQueryConfig.SeriesCountState); err != nil {
+			check.ExitError(fmt.Errorf("invalid value for --series-count-state: %s", cliQueryConfig.SeriesCountState))
+		}
+
+		if cliQueryConfig.CompareOffset != "" {
+			if _, err := model.ParseDuration(cliQueryConfig.CompareOffset); err != nil {
+				check.ExitError(fmt.Errorf("invalid value for --compare-offset: %w", err))
 			}
+		}
+
+		if err := query.ValidateCompareMode(cliQueryConfig.CompareMode); err != nil {
+			check.ExitError(err)
+		}
+
+		if _, err := convertStateToInt(cliQueryConfig.CompareMissingState); err != nil {
+			check.ExitError(fmt.Errorf("invalid value for --compare-missing-state: %s", cliQueryConfig.CompareMissingState))
+		}
 
-		case model.ValMatrix:
-			// Range vector - a set of time series containing a range of data points over time for each time series -> Matrix
-			// An example query for a matrix 'go_goroutines{job="prometheus"}[5m]'
+		if cliQueryConfig.ForecastTarget != "" {
+			if _, err := strconv.ParseFloat(cliQueryConfig.ForecastTarget, 64); err != nil {
+				check.ExitError(fmt.Errorf("invalid value for --forecast-target: %s", cliQueryConfig.ForecastTarget))
+			}
 
-			// Note: Only the latest value will be evaluated, other values will be ignored!
-			matrixVal := result.(model.Matrix)
+			if _, err := model.ParseDuration(cliQueryConfig.ForecastRange); err != nil {
+				check.ExitError(fmt.Errorf("invalid value for --forecast-range: %w", err))
+			}
 
-			for _, samplestream := range matrixVal {
-				samplepair := samplestream.Values[len(samplestream.Values)-1]
+			if step, err := model.ParseDuration(cliQueryConfig.ForecastStep); err != nil || step == 0 {
+				check.ExitError(fmt.Errorf("invalid value for --forecast-step: %s", cliQueryConfig.ForecastStep))
+			}
 
-				numberValue := float64(samplepair.Value)
+			if err := query.ValidateForecastDirection(cliQueryConfig.ForecastDirection); err != nil {
+				check.ExitError(err)
+			}
+		}
 
-				partial := goresult.NewPartialResult()
+		if cliQueryConfig.DutyCycle != "" {
+			if _, err := model.ParseDuration(cliQueryConfig.DutyCycle); err != nil {
+				check.ExitError(fmt.Errorf("invalid value for --duty-cycle: %w", err))
+			}
 
-				if crit.DoesViolate(numberValue) {
-					_ = partial.SetState(check.Critical)
-				} else if warn.DoesViolate(numberValue) {
-					_ = partial.SetState(check.Warning)
-				} else {
-					_ = partial.SetState(check.OK)
-				}
+			if step, err := model.ParseDuration(cliQueryConfig.DutyCycleStep); err != nil || step == 0 {
+				check.ExitError(fmt.Errorf("invalid value for --duty-cycle-step: %s", cliQueryConfig.DutyCycleStep))
+			}
 
-				// Format the metric and RC output for console output
-				partial.Output = generateMetricOutput(samplepair.String(), samplepair.Value.String())
+			if cliQueryConfig.WarningDutyCycle == "" && cliQueryConfig.CriticalDutyCycle == "" {
+				check.ExitError(errors.New("--duty-cycle requires --warning-duty-cycle or --critical-duty-cycle"))
+			}
+		}
 
-				valueString := samplepair.Value.String()
+		if cliQueryConfig.MaxAge != "" {
+			if _, err := model.ParseDuration(cliQueryConfig.MaxAge); err != nil {
+				check.ExitError(fmt.Errorf("invalid value for --max-age: %w", err))
+			}
+		}
 
-				valueNumber, err := strconv.ParseFloat(valueString, 64)
-				if err == nil {
-					pd := generatePerfdata(samplestream.Metric.String(), valueNumber, warn, crit)
+		if _, err := convertStateToInt(cliQueryConfig.StaleState); err != nil {
+			check.ExitError(fmt.Errorf("invalid value for --stale-state: %s", cliQueryConfig.StaleState))
+		}
 
-					// Generate Perfdata from API return
-					if !math.IsInf(numberValue, 0) && !math.IsNaN(numberValue) {
-						partial.Perfdata.Add(&pd)
-					}
-				}
+		for name, spec := range map[string]string{
+			"warning-count":       cliQueryConfig.WarningCount,
+			"critical-count":      cliQueryConfig.CriticalCount,
+			"warning-percent":     cliQueryConfig.WarningPercent,
+			"critical-percent":    cliQueryConfig.CriticalPercent,
+			"warning-duty-cycle":  cliQueryConfig.WarningDutyCycle,
+			"critical-duty-cycle": cliQueryConfig.CriticalDutyCycle,
+			"warning-eval-time":   cliQueryConfig.WarningEvalTime,
+			"critical-eval-time":  cliQueryConfig.CriticalEvalTime,
+			"warning-queue-time":  cliQueryConfig.WarningQueueTime,
+			"critical-queue-time": cliQueryConfig.CriticalQueueTime,
+			"warning-samples":     cliQueryConfig.WarningSamples,
+			"critical-samples":    cliQueryConfig.CriticalSamples,
+		} {
+			if _, err := parseOptionalThreshold(spec); err != nil {
+				check.ExitError(fmt.Errorf("invalid value for --%s: %w", name, err))
+			}
+		}
 
-				overall.AddSubcheck(partial)
+		if cliQueryConfig.MaxSeries >= 0 && cliQueryConfig.MinSeries > cliQueryConfig.MaxSeries {
+			check.ExitError(errors.New("--min-series must not be greater than --max-series"))
+		}
+	},
+	Run: func(_ *cobra.Command, _ []string) {
+		crit, err := check.ParseThreshold(cliQueryConfig.Critical)
+		if err != nil {
+			check.ExitError(err)
+		}
+
+		warn, err := check.ParseThreshold(cliQueryConfig.Warning)
+		if err != nil {
+			check.ExitError(err)
+		}
+
+		overrides := make([]*query.ThresholdOverride, 0, len(cliQueryConfig.Thresholds))
+
+		for _, spec := range cliQueryConfig.Thresholds {
+			o, err := query.ParseThresholdOverride(spec)
+			if err != nil {
+				check.ExitError(err)
 			}
+
+			overrides = append(overrides, o)
+		}
+
+		queries, err := parseQueries(warn, crit)
+		if err != nil {
+			check.ExitError(err)
+		}
+
+		if err := substituteVars(); err != nil {
+			check.ExitError(err)
+		}
+
+		c := cliConfig.NewClient()
+
+		err = c.Connect()
+		if err != nil {
+			check.ExitError(err)
+		}
+
+		ctx, cancel := cliConfig.timeoutContext()
+		defer cancel()
+
+		// Collects the info annotations of the API responses, these are not returned by the v1.API
+		var annotations client.Annotations
+
+		// The quantiles of a classic histogram are evaluated on their own
+		if cliQueryConfig.Histogram != "" {
+			overall, warnings, err := histogramQuery(client.WithAnnotations(ctx, &annotations), c, warn, crit, overrides)
+			exitQuery(overall, warnings, annotations.Infos(), err)
+		}
+
+		// The ratio of two queries is evaluated on its own
+		if cliQueryConfig.Numerator != "" {
+			overall, warnings, err := ratioQuery(client.WithAnnotations(ctx, &annotations), c, warn, crit, overrides)
+			exitQuery(overall, warnings, annotations.Infos(), err)
+		}
+
+		// A single unnamed query is evaluated on its own
+		if len(queries) == 1 && queries[0].Name == "" {
+			overall, warnings, err := evaluateQuery(client.WithAnnotations(ctx, &annotations), c, queries[0], overrides)
+			exitQuery(overall, warnings, annotations.Infos(), err)
+		}
+
+		// Named queries are evaluated concurrently, each one gets its own PartialResult
+		results := make([]goresult.PartialResult, len(queries))
+
+		var wg sync.WaitGroup
+
+		for i, q := range queries {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				results[i] = evaluateNamedQuery(ctx, c, q, overrides)
+			}()
 		}
 
-		if len(warnings) != 0 {
-			appendum := fmt.Sprintf("HTTP Warnings: %v", strings.Join(warnings, ", "))
-			overall.Summary = overall.GetOutput() + appendum
+		wg.Wait()
+
+		var overall goresult.Overall
+
+		for _, r := range results {
+			overall.AddSubcheck(r)
 		}
 
 		check.ExitRaw(overall.GetStatus(), overall.GetOutput())
@@ -193,8 +2039,21 @@ Note: Time range values e.G. 'go_memstats_alloc_bytes_total[0s]' only the latest
 func init() {
 	rootCmd.AddCommand(queryCmd)
 	fs := queryCmd.Flags()
-	fs.StringVarP(&cliQueryConfig.RawQuery, "query", "q", "",
-		"An Prometheus query which will be performed and the value result will be evaluated")
+	fs.StringArrayVarP(&cliQueryConfig.Queries, "query", "q", []string{},
+		"An Prometheus query which will be performed and the value result will be evaluated."+
+			"\nThis parameter can be repeated with named queries e.g.: '--query load=node_load1 --query procs=node_procs_running'")
+	fs.StringArrayVar(&cliQueryConfig.QueryThresholds, "query-threshold", []string{},
+		"Warning and critical thresholds for a named query, missing thresholds fall back to --warning and --critical."+
+			"\nThis parameter can be repeated e.g.: '--query-threshold load:w=5,c=10 --query-threshold procs:c=100'")
+	fs.StringVar(&cliQueryConfig.QueryLibrary, "query-library", "",
+		"Path to a YAML file with a library of queries, used with --query-name")
+	fs.StringVar(&cliQueryConfig.QueryName, "query-name", "",
+		"Name of the query from the --query-library to perform instead of --query."+
+			"\nThe thresholds and the perfdata label of the library are used unless they are set explicitly")
+	fs.StringArrayVar(&cliQueryConfig.Vars, "var", []string{},
+		"Variable for the placeholders ${name} of --query, --numerator, --denominator, --histogram or a library query, in the format <name>=<value>."+
+			"\nThe values are escaped for PromQL strings, use ${name:regex} to escape them for =~ matchers as well."+
+			"\nThis parameter can be repeated e.g.: '--var instance=db01 --var job=node'")
 	fs.BoolVar(&cliQueryConfig.ShowAll, "show-all", false,
 		"Displays all metrics regardless of the status")
 
@@ -205,6 +2064,181 @@ func init() {
 	fs.StringVarP(&cliQueryConfig.Critical, "critical", "c", "20",
 		"The critical threshold for a value")
 
+	fs.StringVar(&cliQueryConfig.WarningCount, "warning-count", "",
+		"The warning threshold for the number of series violating their thresholds."+
+			"\nIf set, the overall state is derived from the number of violating series instead of the worst state")
+	fs.StringVar(&cliQueryConfig.CriticalCount, "critical-count", "",
+		"The critical threshold for the number of series violating their thresholds")
+	fs.StringVar(&cliQueryConfig.WarningPercent, "warning-percent", "",
+		"The warning threshold for the share (0-100) of series violating their thresholds")
+	fs.StringVar(&cliQueryConfig.CriticalPercent, "critical-percent", "",
+		"The critical threshold for the share (0-100) of series violating their thresholds")
+
+	fs.StringArrayVar(&cliQueryConfig.Thresholds, "threshold", []string{},
+		"Warning and critical thresholds for series matching the given label matchers."+
+			"\nThis parameter can be repeated e.g.: '--threshold {mountpoint=\"/var\"}:w=80,c=90 --threshold {env=~\"stag.*\"}:c=95'"+
+			"\nThe first matching entry is used, missing thresholds fall back to --warning and --critical")
+	fs.StringVar(&cliQueryConfig.ValueMap, "value-map", "",
+		"Map exact values to a state and a display text instead of evaluating the thresholds"+
+			"\ne.g.: '0=CRITICAL:down,1=OK:up,2=WARNING:degraded'")
+	fs.StringVar(&cliQueryConfig.StateLabel, "state-label", "",
+		"Use the given label of each series to override its state, series without the label are evaluated against the thresholds."+
+			"\nThe label values 'ok/warning/critical/unknown' are mapped case-insensitive by default")
+	fs.StringVar(&cliQueryConfig.StateLabelMap, "state-label-map", "",
+		"Map additional values of --state-label to a state e.g.: 'healthy=OK,degraded=WARNING,failed=CRITICAL'")
+	fs.StringVar(&cliQueryConfig.UnmappedState, "unmapped-state", "UNKNOWN",
+		"State to assign to values that are not part of --value-map or --state-label-map (0, 1, 2, 3, OK, WARNING, CRITICAL, UNKNOWN)")
+
+	fs.StringVar(&cliQueryConfig.Numerator, "numerator", "",
+		"A Prometheus query for the numerator of a ratio, use instead of --query together with --denominator."+
+			"\nThe thresholds are applied to the ratio of each series")
+	fs.StringVar(&cliQueryConfig.Denominator, "denominator", "",
+		"A Prometheus query for the denominator of a ratio")
+	fs.StringSliceVar(&cliQueryConfig.JoinLabels, "join-label", nil,
+		"The labels used to match the numerator and denominator series, similar to on() in PromQL."+
+			"\nThis parameter can be repeated e.g.: '--join-label job --join-label instance'"+
+			"\nIf no label is given, all labels except the metric name are used")
+	fs.StringVar(&cliQueryConfig.ZeroDenominator, "zero-denominator-state", "UNKNOWN",
+		"State to assign when the denominator of a ratio is 0 (0, 1, 2, 3, OK, WARNING, CRITICAL, UNKNOWN)")
+
+	fs.StringArrayVar(&cliQueryConfig.IncludeLabels, "include-label", []string{},
+		"Only evaluate series with a label matching the regular expression, in the format <label>=<regex>."+
+			"\nThis parameter can be repeated e.g.: '--include-label instance=db.* --include-label job=mysqld'"+
+			"\nNote that repeated --include-label are combined using a union.")
+	fs.StringArrayVar(&cliQueryConfig.ExcludeLabels, "exclude-label", []string{},
+		"Don't evaluate series with a label matching the regular expression, in the format <label>=<regex>."+
+			"\nThis parameter can be repeated e.g.: '--exclude-label mountpoint=/run.* --exclude-label fstype=tmpfs'")
+
+	fs.StringVar(&cliQueryConfig.Humanize, "humanize", "",
+		"Format the values in the output ("+strings.Join(output.Modes, ", ")+"), the perfdata keeps the raw values."+
+			"\nbytes and si use binary and SI prefixes, duration formats seconds and timestamp formats unix timestamps relative to now")
+	fs.StringVar(&cliQueryConfig.OutputTemplate, "output-template", "",
+		"Go template for the output of each series e.g.: 'Disk {{.Labels.mountpoint}} on {{.Labels.instance}} is {{.Value}}% full'."+
+			"\nAvailable fields: .Name .Labels .Value .State, the value is the value evaluated against the thresholds"+
+			"\nAvailable functions: humanize humanize1024 humanizeDuration humanizePercentage humanizeTimestamp")
+	fs.StringVar(&cliQueryConfig.PerfdataLabel, "perfdata-label", "",
+		"Go template for the perfdata label of a series, with the labels of the series as fields e.g.: '{{.instance}}_{{.device}}'."+
+			"\nIf not set, the label is generated from the metric name and all labels")
+	fs.StringVar(&cliQueryConfig.Uom, "uom", "",
+		"Unit of measurement of the perfdata values e.g.: 's', 'B', '%'")
+	fs.StringVar(&cliQueryConfig.Min, "min", "",
+		"Minimum value of the perfdata values")
+	fs.StringVar(&cliQueryConfig.Max, "max", "",
+		"Maximum value of the perfdata values")
+
+	fs.StringVarP(&cliQueryConfig.Aggregate, "aggregate", "a", "",
+		"Aggregation function to reduce the values of a range vector before evaluating the thresholds"+
+			"\n("+strings.Join(query.Aggregations, ", ")+")"+
+			"\nIf not set, only the latest value of a range vector will be evaluated")
+	fs.Float64Var(&cliQueryConfig.Percentile, "percentile", 95,
+		"The percentile (0-100) to calculate when using '--aggregate percentile'")
+	fs.StringVar(&cliQueryConfig.Histogram, "histogram", "",
+		"Name of a classic histogram metric, use instead of --query. The quantiles given with --histogram-quantile"+
+			"\nare calculated from the rate of the _bucket series and each one is evaluated against its thresholds."+
+			"\nThe name may contain a selector e.g.: 'http_request_duration_seconds{job=\"api\"}'")
+	fs.StringVar(&cliQueryConfig.HistogramWindow, "histogram-window", "5m",
+		"Time window of the rate of the _bucket series when using --histogram")
+	fs.StringSliceVar(&cliQueryConfig.HistogramBy, "histogram-by", nil,
+		"Labels to group the _bucket series by when using --histogram, similar to 'sum by' in PromQL."+
+			"\nThis parameter can be repeated e.g.: '--histogram-by job --histogram-by instance'")
+	fs.StringArrayVar(&cliQueryConfig.HistogramQuantiles, "histogram-quantile", []string{},
+		"Quantile (0-1) of a classic or native histogram to evaluate, with optional thresholds in the format <quantile>[:w=<threshold>,c=<threshold>]."+
+			"\nMissing thresholds fall back to --warning and --critical, without this flag the 0.95-quantile is evaluated."+
+			"\nThis parameter can be repeated e.g.: '--histogram-quantile 0.5:w=0.1,c=0.2 --histogram-quantile 0.99:w=1,c=2'")
+	fs.StringVar(&cliQueryConfig.HistogramView, "histogram-view", query.HistogramViewQuantile,
+		"Value of a native histogram to evaluate against the thresholds ("+
+			query.HistogramViewQuantile+", "+query.HistogramViewCount+", "+query.HistogramViewSum+")."+
+			"\nThe quantile view evaluates the quantiles of --histogram-quantile, the other views add them as perfdata")
+
+	fs.StringVar(&cliQueryConfig.CompareOffset, "compare-offset", "",
+		"Compare the result with the result at the given offset (e.g. 1h, 1d, 7d)."+
+			"\nThe thresholds are applied to the difference between the current and the previous value")
+	fs.StringVar(&cliQueryConfig.CompareMode, "compare-mode", query.CompareAbsolute,
+		"Difference to evaluate when using --compare-offset ("+query.CompareAbsolute+", "+query.ComparePercent+")")
+	fs.StringVar(&cliQueryConfig.CompareMissingState, "compare-missing-state", "UNKNOWN",
+		"State to assign when a series has no value at the offset (0, 1, 2, 3, OK, WARNING, CRITICAL, UNKNOWN)")
+
+	fs.StringVar(&cliQueryConfig.ForecastTarget, "forecast-target", "",
+		"Estimate the time until each series reaches the given value, based on a linear regression over --forecast-range."+
+			"\nThe thresholds are applied to the remaining time in seconds e.g.: '-w 604800: -c 86400:'")
+	fs.StringVar(&cliQueryConfig.ForecastRange, "forecast-range", "6h",
+		"Time range of the samples used for the forecast")
+	fs.StringVar(&cliQueryConfig.ForecastStep, "forecast-step", "5m",
+		"Resolution step of the samples used for the forecast")
+	fs.StringVar(&cliQueryConfig.ForecastDirection, "forecast-direction", query.ForecastUp,
+		"Direction in which a series reaches the forecast target ("+query.ForecastUp+", "+query.ForecastDown+")")
+
+	fs.StringVar(&cliQueryConfig.DutyCycle, "duty-cycle", "",
+		"Evaluate the share of samples within the given window e.g. 1h that violate the thresholds, instead of the latest value."+
+			"\nThe shares are evaluated against --warning-duty-cycle and --critical-duty-cycle")
+	fs.StringVar(&cliQueryConfig.DutyCycleStep, "duty-cycle-step", "1m",
+		"Resolution step of the samples used for the duty cycle")
+	fs.StringVar(&cliQueryConfig.WarningDutyCycle, "warning-duty-cycle", "",
+		"The warning threshold for the share (0-100) of samples violating the warning threshold")
+	fs.StringVar(&cliQueryConfig.CriticalDutyCycle, "critical-duty-cycle", "",
+		"The critical threshold for the share (0-100) of samples violating the critical threshold")
+
+	fs.StringVar(&cliQueryConfig.Time, "time", "",
+		"Evaluation time of the query as RFC3339 or unix timestamp or relative to now e.g. -2m (default now)")
+	fs.StringVar(&cliQueryConfig.QueryTimeout, "query-timeout", "",
+		"Evaluation timeout of the query on the Prometheus server e.g. 5s")
+	fs.StringVar(&cliQueryConfig.LookbackDelta, "lookback-delta", "",
+		"Lookback delta of the query, the maximum age of a sample that is still considered for the evaluation e.g. 10m")
+
+	fs.StringVar(&cliQueryConfig.WarningsState, "warnings-state", "",
+		"State to assign to each warning and info annotation returned by the query (0, 1, 2, 3, OK, WARNING, CRITICAL, UNKNOWN)."+
+			"\nIf not set the warnings are appended to the output and don't change the state")
+
+	fs.BoolVar(&cliQueryConfig.Stats, "stats", false,
+		"Request the statistics of the query and add the evaluation time, queue time and total samples as perfdata")
+	fs.StringVar(&cliQueryConfig.WarningEvalTime, "warning-eval-time", "",
+		"The warning threshold for the evaluation time of the query in seconds, implies --stats")
+	fs.StringVar(&cliQueryConfig.CriticalEvalTime, "critical-eval-time", "",
+		"The critical threshold for the evaluation time of the query in seconds, implies --stats")
+	fs.StringVar(&cliQueryConfig.WarningQueueTime, "warning-queue-time", "",
+		"The warning threshold for the time the query waited in the queue in seconds, implies --stats")
+	fs.StringVar(&cliQueryConfig.CriticalQueueTime, "critical-queue-time", "",
+		"The critical threshold for the time the query waited in the queue in seconds, implies --stats")
+	fs.StringVar(&cliQueryConfig.WarningSamples, "warning-samples", "",
+		"The warning threshold for the total number of samples loaded by the query, implies --stats")
+	fs.StringVar(&cliQueryConfig.CriticalSamples, "critical-samples", "",
+		"The critical threshold for the total number of samples loaded by the query, implies --stats")
+
+	fs.StringVar(&cliQueryConfig.MaxAge, "max-age", "",
+		"Maximum age of the latest sample of a series of a range vector result (e.g. 5m, 1h). Older series are considered stale")
+	fs.StringVar(&cliQueryConfig.StaleState, "stale-state", "UNKNOWN",
+		"State to assign to stale series when using --max-age (0, 1, 2, 3, OK, WARNING, CRITICAL, UNKNOWN)")
+
+	fs.StringVar(&cliQueryConfig.EmptyState, "empty-state", "UNKNOWN",
+		"State to assign when the query returns no series (0, 1, 2, 3, OK, WARNING, CRITICAL, UNKNOWN)")
+	fs.StringVar(&cliQueryConfig.EmptyMessage, "empty-message", "Query returned no results",
+		"Message to display when the query returns no series")
+
+	fs.IntVar(&cliQueryConfig.MinSeries, "min-series", 0,
+		"Minimum number of series the query is expected to return")
+	fs.IntVar(&cliQueryConfig.MaxSeries, "max-series", -1,
+		"Maximum number of series the query is expected to return. A negative value disables the check")
+	fs.StringVar(&cliQueryConfig.SeriesCountState, "series-count-state", "CRITICAL",
+		"State to assign when the number of series is outside of --min-series and --max-series (0, 1, 2, 3, OK, WARNING, CRITICAL, UNKNOWN)")
+
+	fs.StringArrayVar(&cliQueryConfig.ExpectSeries, "expect-series", []string{},
+		"Labels of a series the query is expected to return, each missing series is reported on its own."+
+			"\nThis parameter can be repeated e.g.: '--expect-series instance=db01 --expect-series instance=db02,job=mysqld'")
+	fs.StringVar(&cliQueryConfig.ExpectSeriesFile, "expect-series-file", "",
+		"File with the labels of the expected series, one series per line in the format of --expect-series")
+	fs.StringVar(&cliQueryConfig.MissingState, "missing-series-state", "CRITICAL",
+		"State to assign to each missing expected series (0, 1, 2, 3, OK, WARNING, CRITICAL, UNKNOWN)")
+	fs.BoolVar(&cliQueryConfig.ReportUnexpected, "report-unexpected", false,
+		"Report the series that don't match any expected series")
+	fs.StringVar(&cliQueryConfig.UnexpectedState, "unexpected-series-state", "WARNING",
+		"State to assign to each unexpected series when using --report-unexpected (0, 1, 2, 3, OK, WARNING, CRITICAL, UNKNOWN)")
+
+	fs.StringVar(&cliQueryConfig.ExpectString, "expect-string", "",
+		"Regular expression to match against a string result, e.g. a version string")
+	fs.StringVar(&cliQueryConfig.StringMatchState, "string-match-state", "OK",
+		"State to assign when the string result matches --expect-string (0, 1, 2, 3, OK, WARNING, CRITICAL, UNKNOWN)")
+	fs.StringVar(&cliQueryConfig.StringMismatchState, "string-mismatch-state", "CRITICAL",
+		"State to assign when the string result does not match --expect-string (0, 1, 2, 3, OK, WARNING, CRITICAL, UNKNOWN)")
+
 	fs.SortFlags = false
-	_ = queryCmd.MarkFlagRequired("query")
 }
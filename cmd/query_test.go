@@ -1,12 +1,15 @@
 package cmd
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os/exec"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestQuery_ConnectionRefused(t *testing.T) {
@@ -61,7 +64,7 @@ func TestQueryCmd(t *testing.T) {
 				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]},"warnings": ["hic sunt dracones", "foo"]}`))
 			})),
 			args:     []string{"run", "../main.go", "query", "--query", "foo"},
-			expected: "[UNKNOWN] - No status information\nHTTP Warnings: hic sunt dracones, foo\n\nexit status 3\n",
+			expected: "[UNKNOWN] - states: unknowns=1\nHTTP Warnings: hic sunt dracones, foo\n\\_ [UNKNOWN] Query returned no results\n\nexit status 3\n",
 		},
 		{
 			name: "query-no-such-metric",
@@ -70,7 +73,7 @@ func TestQueryCmd(t *testing.T) {
 				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
 			})),
 			args:     []string{"run", "../main.go", "query", "--query", "foo"},
-			expected: "[UNKNOWN] - No status information\n\nexit status 3\n",
+			expected: "[UNKNOWN] - states: unknowns=1\n\\_ [UNKNOWN] Query returned no results\n\nexit status 3\n",
 		},
 		{
 			name: "query-no-such-matrix",
@@ -79,7 +82,34 @@ func TestQueryCmd(t *testing.T) {
 				w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[]}}`))
 			})),
 			args:     []string{"run", "../main.go", "query", "--query", "foo"},
-			expected: "[UNKNOWN] - No status information\n\nexit status 3\n",
+			expected: "[UNKNOWN] - states: unknowns=1\n\\_ [UNKNOWN] Query returned no results\n\nexit status 3\n",
+		},
+		{
+			name: "query-empty-state",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "foo", "--empty-state", "critical", "--empty-message", "Target gone"},
+			expected: "[CRITICAL] - states: critical=1\n\\_ [CRITICAL] Target gone\n\nexit status 2\n",
+		},
+		{
+			name: "query-min-series",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write(loadTestdata(queryTestDataSet2))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up{job=\"prometheus\"}", "--min-series", "2", "--series-count-state", "warning"},
+			expected: "[WARNING] - states: warning=1 ok=1\n\\_ [OK]  up{instance=\"localhost\", job=\"prometheus\"} - value: 1\n\\_ [WARNING] series count: 1 - expected at least 2\n|up_instance_localhost_job_prometheus=1;10;20 series=1\n\nexit status 1\n",
+		},
+		{
+			name: "query-max-series",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write(loadTestdata(queryTestDataSet2))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up{job=\"prometheus\"}", "--max-series", "0"},
+			expected: "[CRITICAL] - states: critical=1 ok=1\n\\_ [OK]  up{instance=\"localhost\", job=\"prometheus\"} - value: 1\n\\_ [CRITICAL] series count: 1 - expected at most 0\n|up_instance_localhost_job_prometheus=1;10;20 series=1\n\nexit status 2\n",
 		},
 		{
 			name: "query-scalar",
@@ -88,7 +118,16 @@ func TestQueryCmd(t *testing.T) {
 				w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1670339013.992,"1"]}}`))
 			})),
 			args:     []string{"run", "../main.go", "query", "--query", "1"},
-			expected: "[UNKNOWN] - scalar value results are not supported (*errors.errorString)\nexit status 3\n",
+			expected: "[OK] - states: ok=1\n\\_ [OK]  scalar - value: 1\n|scalar=1;10;20\n\n",
+		},
+		{
+			name: "query-scalar-critical",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1670339013.992,"300"]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "time() - 1670338713.992"},
+			expected: "[CRITICAL] - states: critical=1\n\\_ [CRITICAL]  scalar - value: 300\n|scalar=300;10;20\n\nexit status 2\n",
 		},
 		{
 			name: "query-string",
@@ -97,7 +136,25 @@ func TestQueryCmd(t *testing.T) {
 				w.Write([]byte(`{"status":"success","data":{"resultType":"string","result":[1670339013.992,"up"]}}`))
 			})),
 			args:     []string{"run", "../main.go", "query", "--query", "up"},
-			expected: "[UNKNOWN] - string value results are not supported (*errors.errorString)\nexit status 3\n",
+			expected: "[UNKNOWN] - string value results require --expect-string (*errors.errorString)\nexit status 3\n",
+		},
+		{
+			name: "query-string-match",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"string","result":[1670339013.992,"v2.45.0"]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "\"v2.45.0\"", "--expect-string", "^v2\\."},
+			expected: "[OK] - states: ok=1\n\\_ [OK]  string - value: v2.45.0 matches ^v2\\.\n\n",
+		},
+		{
+			name: "query-string-mismatch",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"string","result":[1670339013.992,"v1.8.2"]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "\"v1.8.2\"", "--expect-string", "^v2\\.", "--string-mismatch-state", "warning"},
+			expected: "[WARNING] - states: warning=1\n\\_ [WARNING]  string - value: v1.8.2 does not match ^v2\\.\n\nexit status 1\n",
 		},
 		{
 			name: "query-matrix-exists",
@@ -195,6 +252,729 @@ func TestExtendedQueryCmd(t *testing.T) {
 			args:     []string{"run", "../main.go", "query", "--query", "up", "-w", "10", "-c", "20"},
 			expected: "WARNING] - states: warning=1 ok=2\n\\_ [OK]  1 @[1696589452.987] - value: 1\n\\_ [WARNING]  15 @[1696589449.089] - value: 15\n\\_ [OK]  1 @[1696589449.369] - value: 1\n|up_instance_localhost:9100_job_node=1;10;20 up_instance_localhost:9104_job_mysqld=15;10;20 up_instance_localhost:9117_job_apache=1;10;20\n\nexit status 1\n",
 		},
+		{
+			name: "vector-threshold-override",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"disk_used_percent","mountpoint":"/"},"value":[1696589905.608,"85"]},{"metric":{"__name__":"disk_used_percent","mountpoint":"/var"},"value":[1696589905.608,"85"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "disk_used_percent", "-w", "70", "-c", "80", "--threshold", `{mountpoint="/var"}:w=90,c=95`},
+			expected: "[CRITICAL] - states: critical=1 ok=1\n\\_ [CRITICAL]  disk_used_percent{mountpoint=\"/\"} - value: 85\n\\_ [OK]  disk_used_percent{mountpoint=\"/var\"} - value: 85\n|disk_used_percent_mountpoint_/=85;70;80 disk_used_percent_mountpoint_/var=85;90;95\n\nexit status 2\n",
+		},
+		{
+			name: "vector-threshold-override-invalid",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "disk_used_percent", "--threshold", `{mountpoint="/var"}`},
+			expected: "[UNKNOWN] - expected ':' after selector in threshold",
+		},
+		{
+			name: "named-queries",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				switch r.FormValue("query") {
+				case "node_load1":
+					w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"node_load1","instance":"db01"},"value":[1696589905.608,"7"]}]}}`))
+				case "node_procs_running":
+					w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"node_procs_running","instance":"db01"},"value":[1696589905.608,"7"]}]}}`))
+				}
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "load=node_load1", "--query", "procs=node_procs_running", "--query-threshold", "load:w=5,c=10", "-w", "50", "-c", "100"},
+			expected: "[WARNING] - states: warning=1 ok=1\n\\_ [WARNING] load - states: warning=1\n    \\_ [WARNING]  node_load1{instance=\"db01\"} - value: 7\n\\_ [OK] procs - states: ok=1\n    \\_ [OK]  node_procs_running{instance=\"db01\"} - value: 7\n|load_node_load1_instance_db01=7;5;10 procs_node_procs_running_instance_db01=7;50;100\n\nexit status 1\n",
+		},
+		{
+			name: "named-queries-error",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.FormValue("query") == "invalid(" {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"unexpected end of input"}`))
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1670339013.992,"1"]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "one=1", "--query", "broken=invalid("},
+			expected: "[UNKNOWN] - states: unknowns=1 ok=1\n\\_ [OK] one - states: ok=1\n    \\_ [OK]  scalar - value: 1\n\\_ [UNKNOWN] broken - bad_data: unexpected end of input\n|one_scalar=1;10;20\n\nexit status 3\n",
+		},
+		{
+			name: "named-queries-missing-name",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "one=1", "--query", "up"},
+			expected: "[UNKNOWN] - please specify a name for each query when using multiple queries (--query name=expr): up",
+		},
+		{
+			name: "vector-compare-offset-percent",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				// The comparison query is performed a week before the current one
+				ts, _ := strconv.ParseFloat(r.FormValue("time"), 64)
+				if time.Since(time.Unix(int64(ts), 0)) > 24*time.Hour {
+					w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"api"},"value":[1696589905.608,"100"]},{"metric":{"job":"web"},"value":[1696589905.608,"200"]}]}}`))
+					return
+				}
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"api"},"value":[1696589905.608,"130"]},{"metric":{"job":"web"},"value":[1696589905.608,"210"]},{"metric":{"job":"db"},"value":[1696589905.608,"5"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "sum by (job) (rate(requests_total[5m]))", "--compare-offset", "7d", "--compare-mode", "percent", "-w", "~:10", "-c", "~:25", "--compare-missing-state", "ok"},
+			expected: "[CRITICAL] - states: critical=1 ok=2\n\\_ [CRITICAL]  {job=\"api\"} - value: 130 - 1w ago: 100 - change: 30%\n\\_ [OK]  {job=\"web\"} - value: 210 - 1w ago: 200 - change: 5%\n\\_ [OK]  {job=\"db\"} - value: 5 - 1w ago: no value\n|_job_api=130 _job_api_previous=100 _job_api_delta=30%;~:10;~:25 _job_web=210 _job_web_previous=200 _job_web_delta=5%;~:10;~:25\n\nexit status 2\n",
+		},
+		{
+			name: "forecast-time-to-target",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				// Grows by 10 every 10 minutes and reaches 100 in 30 minutes after the end of the range
+				now, _ := strconv.ParseFloat(r.FormValue("end"), 64)
+				fmt.Fprintf(w, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"mountpoint":"/"},"values":[[%f,"40"],[%f,"50"],[%f,"60"]]},{"metric":{"mountpoint":"/var"},"values":[[%f,"60"],[%f,"50"],[%f,"40"]]}]}}`,
+					now-1800, now-1200, now-600, now-1800, now-1200, now-600)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "disk_used", "--forecast-target", "100", "-w", "7200:", "-c", "3600:"},
+			expected: "[CRITICAL] - states: critical=1 ok=1\n\\_ [CRITICAL]  {mountpoint=\"/\"} - value: 60 - reaches 100 in 30m at ",
+		},
+		{
+			name: "duty-cycle",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"instance":"db01"},"values":[[1696589000,"50"],[1696589060,"95"],[1696589120,"85"],[1696589180,"60"],[1696589240,"99"]]},{"metric":{"instance":"db02"},"values":[[1696589000,"50"],[1696589060,"95"],[1696589120,"60"],[1696589180,"60"],[1696589240,"60"]]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "cpu_usage", "--duty-cycle", "5m", "-w", "80", "-c", "90", "--warning-duty-cycle", "50", "--critical-duty-cycle", "50"},
+			expected: "[WARNING] - states: warning=1 ok=1\n\\_ [WARNING]  {instance=\"db01\"} - 60% of 5 samples over warning, 40% over critical\n\\_ [OK]  {instance=\"db02\"} - 20% of 5 samples over warning, 20% over critical\n|_instance_db01_warning_duty_cycle=60%;50;;0;100 _instance_db01_critical_duty_cycle=40%;;50;0;100",
+		},
+		{
+			name: "duty-cycle-no-thresholds",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "cpu_usage", "--duty-cycle", "1h"},
+			expected: "[UNKNOWN] - --duty-cycle requires --warning-duty-cycle or --critical-duty-cycle",
+		},
+		{
+			name: "forecast-crossing-time",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				now, _ := strconv.ParseFloat(r.FormValue("end"), 64)
+				fmt.Fprintf(w, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"mountpoint":"/"},"values":[[%f,"40"],[%f,"50"],[%f,"60"]]}]}}`,
+					now-1800, now-1200, now-600)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "disk_used", "--forecast-target", "100", "--time", "2023-10-06T10:58:25Z", "-w", "7200:", "-c", "3600:"},
+			expected: "[CRITICAL] - states: critical=1\n\\_ [CRITICAL]  {mountpoint=\"/\"} - value: 60 - reaches 100 in 30m at 2023-10-06T11:28:25Z\n|_mountpoint_/_time_to_target=1800s;7200:;3600:\n\nexit status 2\n",
+		},
+		{
+			name: "forecast-will-not-reach",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				now := float64(time.Now().Unix())
+				fmt.Fprintf(w, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"mountpoint":"/var"},"values":[[%f,"60"],[%f,"50"],[%f,"40"]]}]}}`,
+					now-1800, now-1200, now-600)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "disk_used", "--forecast-target", "100", "-w", "7200:", "-c", "3600:"},
+			expected: "[OK] - states: ok=1\n\\_ [OK]  {mountpoint=\"/var\"} - value: 40 - will not reach 100\n\n",
+		},
+		{
+			name: "matrix-max-age-raises-state",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"instance":"a"},"values":[[1696582705,"50"]]},{"metric":{"instance":"b"},"values":[[1696582705,"NaN"]]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up[1d]", "--time", "2023-10-06T10:58:25Z", "--max-age", "5m", "--stale-state", "warning"},
+			expected: "[CRITICAL] - states: critical=1 warning=1\n\\_ [CRITICAL]  50 @[1696582705] - value: 50 - stale: last sample 2h ago\n\\_ [WARNING]  NaN @[1696582705] - value: NaN - stale: last sample 2h ago\n|_instance_a=50;10;20 _instance_a_age=7200s _instance_b_age=7200s\n\nexit status 2\n",
+		},
+		{
+			name: "matrix-max-age",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				now := float64(time.Now().Unix())
+				fmt.Fprintf(w, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"instance":"a"},"values":[[%f,"1"]]},{"metric":{"instance":"b"},"values":[[%f,"1"]]}]}}`, now, now-7200)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up[1d]", "--max-age", "5m", "--stale-state", "warning"},
+			expected: "[WARNING] - states: warning=1 ok=1\n\\_ [OK]  1 @[",
+		},
+		{
+			name: "vector-max-age",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"instance":"a"},"value":[1696589905.608,"1"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "last_over_time(up[1d])", "--max-age", "5m"},
+			expected: "[UNKNOWN] - --max-age requires a range vector result, e.g. up[5m]",
+		},
+		{
+			name: "vector-violation-count",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"instance":"a"},"value":[1696589905.608,"1"]},{"metric":{"instance":"b"},"value":[1696589905.608,"25"]},{"metric":{"instance":"c"},"value":[1696589905.608,"2"]},{"metric":{"instance":"d"},"value":[1696589905.608,"15"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "node_load1", "--warning-count", "1", "--critical-count", "3"},
+			expected: "[WARNING] - states: warning=1\n\\_ [WARNING] 2 of 4 series violate the thresholds (50%)\n    \\_ [OK]  {instance=\"a\"} - value: 1\n    \\_ [CRITICAL]  {instance=\"b\"} - value: 25\n    \\_ [OK]  {instance=\"c\"} - value: 2\n    \\_ [WARNING]  {instance=\"d\"} - value: 15\n|violations=2;1;3 violations_percent=50%;;;0;100 _instance_a=1;10;20 _instance_b=25;10;20 _instance_c=2;10;20 _instance_d=15;10;20\n\nexit status 1\n",
+		},
+		{
+			name: "vector-violation-percent",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"instance":"a"},"value":[1696589905.608,"1"]},{"metric":{"instance":"b"},"value":[1696589905.608,"25"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "node_load1", "--warning-percent", "60", "--critical-percent", "80"},
+			expected: "[OK] - states: ok=1\n\\_ [OK] 1 of 2 series violate the thresholds (50%)\n",
+		},
+		{
+			name: "vector-ratio",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				if r.FormValue("query") == "errors" {
+					w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"errors","job":"api"},"value":[1696589905.608,"30"]},{"metric":{"__name__":"errors","job":"web"},"value":[1696589905.608,"1"]},{"metric":{"__name__":"errors","job":"db"},"value":[1696589905.608,"0"]}]}}`))
+					return
+				}
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"requests","job":"api"},"value":[1696589905.608,"100"]},{"metric":{"__name__":"requests","job":"web"},"value":[1696589905.608,"100"]},{"metric":{"__name__":"requests","job":"db"},"value":[1696589905.608,"0"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--numerator", "errors", "--denominator", "requests", "--join-label", "job", "-w", "0.1", "-c", "0.2", "--zero-denominator-state", "OK"},
+			expected: "[CRITICAL] - states: critical=1 ok=2\n\\_ [CRITICAL]  ratio{job=\"api\"} - value: 0.3 (30 / 100)\n\\_ [OK]  ratio{job=\"web\"} - value: 0.01 (1 / 100)\n\\_ [OK]  ratio{job=\"db\"} - 0 / 0 - denominator is 0\n|ratio_job_api=0.3;0.1;0.2 ratio_job_web=0.01;0.1;0.2\n\nexit status 2\n",
+		},
+		{
+			name: "vector-ratio-unmatched",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				if r.FormValue("query") == "errors" {
+					w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"errors","job":"api"},"value":[1696589905.608,"1"]}]}}`))
+					return
+				}
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"requests","job":"web"},"value":[1696589905.608,"100"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--numerator", "errors", "--denominator", "requests"},
+			expected: "[UNKNOWN] - states: unknowns=1\n\\_ [UNKNOWN]  errors{job=\"api\"} - no matching denominator series\n",
+		},
+		{
+			name: "ratio-missing-denominator",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--numerator", "errors"},
+			expected: "[UNKNOWN] - please specify both --numerator and --denominator",
+		},
+		{
+			name: "vector-native-histogram",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"http_request_duration_seconds","job":"api"},"histogram":[1696589905.608,{"count":"100","sum":"25","buckets":[[0,"0","0.1","50"],[0,"0.1","0.5","40"],[0,"0.5","1","10"]]}]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "http_request_duration_seconds", "-w", "0.5", "-c", "0.8", "--histogram-quantile", "0.5:w=0.05", "--histogram-quantile", "0.95"},
+			expected: "[WARNING] - states: warning=1\n\\_ [WARNING]  http_request_duration_seconds{job=\"api\"} - quantile 0.5: 0.1, quantile 0.95: 0.75 (count: 100, sum: 25)\n|http_request_duration_seconds_job_api_count=100 http_request_duration_seconds_job_api_sum=25 http_request_duration_seconds_job_api_quantile_0.5=0.1;0.05;0.8 http_request_duration_seconds_job_api_quantile_0.95=0.75;0.5;0.8\n\nexit status 1\n",
+		},
+		{
+			name: "matrix-native-histogram-count",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"http_request_duration_seconds","job":"api"},"histograms":[[1696589845.608,{"count":"50","sum":"10","buckets":[[0,"0","0.1","50"]]}],[1696589905.608,{"count":"100","sum":"25","buckets":[[0,"0","0.1","50"],[0,"0.1","0.5","40"],[0,"0.5","1","10"]]}]]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "http_request_duration_seconds[1m]", "-w", "200", "-c", "300", "--histogram-view", "count", "--histogram-quantile", "0.5"},
+			expected: "[OK] - states: ok=1\n\\_ [OK]  http_request_duration_seconds{job=\"api\"} - count: 100 (count: 100, sum: 25)\n|http_request_duration_seconds_job_api_count=100;200;300 http_request_duration_seconds_job_api_sum=25 http_request_duration_seconds_job_api_quantile_0.5=0.1\n\n",
+		},
+		{
+			name: "classic-histogram",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				if r.FormValue("query") != `sum by (le, job) (rate(http_request_duration_seconds_bucket{job="api"}[10m]))` {
+					w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+					return
+				}
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"api","le":"0.1"},"value":[1696589905.608,"50"]},{"metric":{"job":"api","le":"0.5"},"value":[1696589905.608,"90"]},{"metric":{"job":"api","le":"1"},"value":[1696589905.608,"100"]},{"metric":{"job":"api","le":"+Inf"},"value":[1696589905.608,"100"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--histogram", `http_request_duration_seconds{job="api"}`, "--histogram-window", "10m", "--histogram-by", "job", "--histogram-quantile", "0.5:w=0.05", "--histogram-quantile", "0.95", "-w", "0.5", "-c", "1"},
+			expected: "[WARNING] - states: warning=2\n\\_ [WARNING]  http_request_duration_seconds{job=\"api\", quantile=\"0.5\"} - value: 0.1\n\\_ [WARNING]  http_request_duration_seconds{job=\"api\", quantile=\"0.95\"} - value: 0.75\n|http_request_duration_seconds_job_api_quantile_0.5=0.1;0.05;1 http_request_duration_seconds_job_api_quantile_0.95=0.75;0.5;1\n\nexit status 1\n",
+		},
+		{
+			name: "histogram-buckets-default-percentile",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"api","le":"0.1"},"value":[1696589905.608,"50"]},{"metric":{"job":"api","le":"0.5"},"value":[1696589905.608,"90"]},{"metric":{"job":"api","le":"1"},"value":[1696589905.608,"100"]},{"metric":{"job":"api","le":"+Inf"},"value":[1696589905.608,"100"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--histogram", "http_request_duration_seconds", "--histogram-by", "job", "-w", "0.5", "-c", "1"},
+			expected: "[WARNING] - states: warning=1\n\\_ [WARNING]  http_request_duration_seconds{job=\"api\", quantile=\"0.95\"} - value: 0.75\n|http_request_duration_seconds_job_api_quantile_0.95=0.75;0.5;1\n\nexit status 1\n",
+		},
+		{
+			name: "histogram-buckets-invalid-quantile",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--histogram", "http_request_duration_seconds", "--histogram-quantile", "0.99:x=1"},
+			expected: "[UNKNOWN] - unknown threshold 'x' in threshold: 0.99:x=1",
+		},
+		{
+			name: "histogram-buckets-percentile",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--histogram", "http_request_duration_seconds", "--histogram-quantile", "95"},
+			expected: "[UNKNOWN] - expected a quantile between 0 and 1 in histogram quantile: 95",
+		},
+		{
+			name: "vector-expect-series",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"up","instance":"db01"},"value":[1696589905.608,"1"]},{"metric":{"__name__":"up","instance":"db03"},"value":[1696589905.608,"1"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up", "-w", "0:", "-c", "1:", "--expect-series", "instance=db01", "--expect-series", "instance=db02", "--report-unexpected"},
+			expected: "[CRITICAL] - states: critical=1 warning=1 ok=2\n\\_ [OK]  up{instance=\"db01\"} - value: 1\n\\_ [OK]  up{instance=\"db03\"} - value: 1\n\\_ [CRITICAL]  {instance=\"db02\"} - expected series is missing\n\\_ [WARNING]  up{instance=\"db03\"} - series is not expected\n",
+		},
+		{
+			name: "vector-expect-series-invalid",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up", "--expect-series", "db01"},
+			expected: "[UNKNOWN] - invalid expected series: expected <label>=<value> in expected series: db01",
+		},
+		{
+			name: "vector-label-filter",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"mountpoint":"/"},"value":[1696589905.608,"50"]},{"metric":{"mountpoint":"/run"},"value":[1696589905.608,"99"]},{"metric":{"mountpoint":"/run/user/1000"},"value":[1696589905.608,"99"]},{"metric":{"mountpoint":"/var"},"value":[1696589905.608,"85"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "disk_used_percent", "-w", "80", "-c", "90", "--exclude-label", "mountpoint=/run.*"},
+			expected: "[WARNING] - states: warning=1 ok=1 - filtered series: 2\n\\_ [OK]  {mountpoint=\"/\"} - value: 50\n\\_ [WARNING]  {mountpoint=\"/var\"} - value: 85\n|_mountpoint_/=50;80;90 _mountpoint_/var=85;80;90\n\nexit status 1\n",
+		},
+		{
+			name: "vector-label-filter-include",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"mountpoint":"/"},"value":[1696589905.608,"50"]},{"metric":{"mountpoint":"/run"},"value":[1696589905.608,"99"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "disk_used_percent", "-w", "80", "-c", "90", "--include-label", "mountpoint=/var"},
+			expected: "[UNKNOWN] - states: unknowns=1 - filtered series: 2\n\\_ [UNKNOWN] Query returned no results\n",
+		},
+		{
+			name: "vector-perfdata-label",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"disk_used_percent","instance":"db01:9100","device":"sda","job":"node"},"values":[[1696589905.608,"50"]]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "disk_used_percent[5m]", "-w", "80", "-c", "90", "--perfdata-label", "{{.instance}}_{{.device}}{{.missing}}", "--uom", "%", "--min", "0", "--max", "100", "--max-age", "100y"},
+			expected: "|db01:9100_sda=50%;80;90;0;100 db01:9100_sda_age=",
+		},
+		{
+			name: "vector-perfdata-label-invalid",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up", "--perfdata-label", "{{.instance"},
+			expected: "[UNKNOWN] - invalid value for --perfdata-label: ",
+		},
+		{
+			name: "vector-output-template",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"disk_used_ratio","instance":"db01","mountpoint":"/var"},"value":[1696589905.608,"0.93"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "disk_used_ratio", "-w", "0.8", "-c", "0.9", "--output-template", "Disk {{.Labels.mountpoint}} on {{.Labels.instance}} is {{humanizePercentage .Value}} full ({{.State}})"},
+			expected: "[CRITICAL] - states: critical=1\n\\_ [CRITICAL] Disk /var on db01 is 93% full (CRITICAL)\n|disk_used_ratio_instance_db01_mountpoint_/var=0.93;0.8;0.9\n\nexit status 2\n",
+		},
+		{
+			name: "matrix-output-template-stale",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"backup_success","instance":"db01"},"values":[[1696582705,"1"]]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "backup_success[1d]", "-w", "1:", "-c", "1:", "--time", "2023-10-06T10:58:25Z", "--max-age", "1h", "--stale-state", "warning", "--output-template", "Backup of {{.Labels.instance}} ({{.State}})"},
+			expected: "[WARNING] - states: warning=1\n\\_ [WARNING] Backup of db01 (WARNING)\n|backup_success_instance_db01=1;1:;1: backup_success_instance_db01_age=7200s\n\nexit status 1\n",
+		},
+		{
+			name: "matrix-aggregate-output-template-stale",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"backup_success","instance":"db01"},"values":[[1696582705,"1"]]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "backup_success[1d]", "-w", "1:", "-c", "1:", "--aggregate", "min", "--time", "2023-10-06T10:58:25Z", "--max-age", "1h", "--stale-state", "warning", "--output-template", "Backup of {{.Labels.instance}} ({{.State}})"},
+			expected: "[WARNING] - states: warning=1\n\\_ [WARNING] Backup of db01 (WARNING)\n",
+		},
+		{
+			name: "vector-humanize-bytes",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"node_memory_MemAvailable_bytes","instance":"db01"},"value":[1696589905.608,"1073741824"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "node_memory_MemAvailable_bytes", "-w", "536870912:", "-c", "268435456:", "--humanize", "bytes"},
+			expected: "[OK] - states: ok=1\n\\_ [OK]  node_memory_MemAvailable_bytes{instance=\"db01\"} - value: 1GiB\n|node_memory_MemAvailable_bytes_instance_db01=1073741824;536870912:;268435456:\n\n",
+		},
+		{
+			name: "vector-humanize-invalid",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up", "--humanize", "percent"},
+			expected: "[UNKNOWN] - invalid humanize mode 'percent', must be one of: bytes, si, duration, timestamp",
+		},
+		{
+			name: "vector-value-map",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"raid_state","device":"md0"},"value":[1696589905.608,"1"]},{"metric":{"__name__":"raid_state","device":"md1"},"value":[1696589905.608,"2"]},{"metric":{"__name__":"raid_state","device":"md2"},"value":[1696589905.608,"5"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "raid_state", "--value-map", "0=CRITICAL:down,1=OK:up,2=WARNING:degraded", "--unmapped-state", "CRITICAL"},
+			expected: "[CRITICAL] - states: critical=1 warning=1 ok=1\n\\_ [OK]  raid_state{device=\"md0\"} - value: 1 (up)\n\\_ [WARNING]  raid_state{device=\"md1\"} - value: 2 (degraded)\n\\_ [CRITICAL]  raid_state{device=\"md2\"} - value: 5\n|raid_state_device_md0=1 raid_state_device_md1=2 raid_state_device_md2=5\n\nexit status 2\n",
+		},
+		{
+			name: "vector-value-map-invalid",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up", "--value-map", "0=DOWN"},
+			expected: "[UNKNOWN] - invalid state 'DOWN' in value map: 0=DOWN",
+		},
+		{
+			name: "vector-state-label",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"health_status","state":"Healthy"},"value":[1696589905.608,"1"]},{"metric":{"__name__":"health_status","state":"degraded"},"value":[1696589905.608,"1"]},{"metric":{"__name__":"health_status","state":"CRITICAL"},"value":[1696589905.608,"1"]},{"metric":{"__name__":"health_status"},"value":[1696589905.608,"1"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "health_status", "--state-label", "state", "--state-label-map", "healthy=OK,degraded=WARNING"},
+			expected: "[CRITICAL] - states: critical=1 warning=1 ok=2\n\\_ [OK]  health_status{state=\"Healthy\"} - value: 1\n\\_ [WARNING]  health_status{state=\"degraded\"} - value: 1\n\\_ [CRITICAL]  health_status{state=\"CRITICAL\"} - value: 1\n\\_ [OK]  health_status - value: 1\n",
+		},
+		{
+			name: "vector-state-label-unmapped",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"health_status","state":"rebuilding"},"value":[1696589905.608,"1"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "health_status", "--state-label", "state"},
+			expected: "[UNKNOWN] - states: unknowns=1\n\\_ [UNKNOWN]  health_status{state=\"rebuilding\"} - value: 1\n",
+		},
+		{
+			name: "vector-query-stats",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				if r.FormValue("stats") != "all" {
+					w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"up","instance":"localhost"},"value":[1696589905.608,"1"]}]}}`))
+					return
+				}
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"up","instance":"localhost"},"value":[1696589905.608,"1"]}],"stats":{"timings":{"evalTotalTime":8.5,"resultSortTime":0,"queryPreparationTime":0.01,"innerEvalTime":8.4,"execQueueTime":0.5,"execTotalTime":9},"samples":{"totalQueryableSamples":1200,"peakSamples":100}}}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up", "--warning-eval-time", "5", "--critical-eval-time", "10", "--critical-samples", "100000"},
+			expected: "[WARNING] - states: warning=1 ok=1\n\\_ [OK]  up{instance=\"localhost\"} - value: 1\n\\_ [WARNING] query stats - eval time: 8.5s, queue time: 0.5s, samples: 1200\n|up_instance_localhost=1;10;20 query_eval_time=8.5s;5;10 query_queue_time=0.5s query_samples=1200;;100000\n\nexit status 1\n",
+		},
+		{
+			name: "vector-query-stats-missing",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"up","instance":"localhost"},"value":[1696589905.608,"1"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up", "--stats"},
+			expected: "[UNKNOWN] - states: unknowns=1 ok=1\n\\_ [OK]  up{instance=\"localhost\"} - value: 1\n\\_ [UNKNOWN] query stats - response does not contain query statistics\n",
+		},
+		{
+			name: "vector-ratio-stats",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				if r.FormValue("query") == "errors" {
+					w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"errors","job":"api"},"value":[1696589905.608,"1"]}],"stats":{"timings":{"evalTotalTime":2.5,"execQueueTime":0.25},"samples":{"totalQueryableSamples":200}}}}`))
+					return
+				}
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"requests","job":"api"},"value":[1696589905.608,"100"]}],"stats":{"timings":{"evalTotalTime":3,"execQueueTime":0.5},"samples":{"totalQueryableSamples":1000}}}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--numerator", "errors", "--denominator", "requests", "--warning-eval-time", "5"},
+			expected: "[WARNING] - states: warning=1 ok=1\n\\_ [OK]  ratio{job=\"api\"} - value: 0.01 (1 / 100)\n\\_ [WARNING] query stats - eval time: 5.5s, queue time: 0.75s, samples: 1200\n",
+		},
+		{
+			name: "vector-compare-offset-stats",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				ts, _ := strconv.ParseFloat(r.FormValue("time"), 64)
+				if time.Since(time.Unix(int64(ts), 0)) > 24*time.Hour {
+					w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"api"},"value":[1696589905.608,"100"]}],"stats":{"timings":{"evalTotalTime":2.5,"execQueueTime":0.25},"samples":{"totalQueryableSamples":200}}}}`))
+					return
+				}
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"api"},"value":[1696589905.608,"105"]}],"stats":{"timings":{"evalTotalTime":3,"execQueueTime":0.5},"samples":{"totalQueryableSamples":1000}}}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "sum by (job) (rate(requests_total[5m]))", "--compare-offset", "7d", "--warning-eval-time", "5"},
+			expected: "[WARNING] - states: warning=1 ok=1\n\\_ [OK]  {job=\"api\"} - value: 105 - 1w ago: 100 - change: 5\n\\_ [WARNING] query stats - eval time: 5.5s, queue time: 0.75s, samples: 1200\n",
+		},
+		{
+			name: "histogram-buckets-stats",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				if r.FormValue("stats") != "all" {
+					w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+					return
+				}
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"api","le":"1"},"value":[1696589905.608,"100"]},{"metric":{"job":"api","le":"+Inf"},"value":[1696589905.608,"100"]}],"stats":{"timings":{"evalTotalTime":0.5,"execQueueTime":0.1},"samples":{"totalQueryableSamples":400}}}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--histogram", "http_request_duration_seconds", "--histogram-by", "job", "--critical-samples", "300"},
+			expected: "[CRITICAL] - states: critical=1 ok=1\n\\_ [OK]  http_request_duration_seconds{job=\"api\", quantile=\"0.95\"} - value: 0.95\n\\_ [CRITICAL] query stats - eval time: 0.5s, queue time: 0.1s, samples: 400\n",
+		},
+		{
+			name: "vector-warnings-state",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","warnings":["partial response: store db01 unavailable"],"infos":["PromQL info: metric might not be a counter, name does not end in _total/_sum/_count/_bucket: \"up\""],"data":{"resultType":"vector","result":[{"metric":{"__name__":"up","instance":"localhost"},"value":[1696589905.608,"1"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "rate(up[5m])", "--warnings-state", "WARNING"},
+			expected: "[WARNING] - states: warning=2 ok=1\n\\_ [OK]  up{instance=\"localhost\"} - value: 1\n\\_ [WARNING] warning: partial response: store db01 unavailable\n\\_ [WARNING] info: PromQL info: metric might not be a counter, name does not end in _total/_sum/_count/_bucket: \"up\"\n",
+		},
+		{
+			name: "named-query-warnings-state",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","infos":["PromQL info: metric might not be a counter"],"data":{"resultType":"vector","result":[{"metric":{"__name__":"up","instance":"localhost"},"value":[1696589905.608,"1"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up=rate(up[5m])", "--warnings-state", "CRITICAL"},
+			expected: "[CRITICAL] - states: critical=1\n\\_ [CRITICAL] up - states: critical=1 ok=1\n    \\_ [OK]  up{instance=\"localhost\"} - value: 1\n    \\_ [CRITICAL] info: PromQL info: metric might not be a counter\n",
+		},
+		{
+			name: "vector-time-options",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"time":"%s","timeout":"%s","lookback_delta":"%s"},"value":[1696589905,"1"]}]}}`,
+					r.FormValue("time"), r.FormValue("timeout"), r.FormValue("lookback_delta"))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up", "--time", "2023-10-06T10:58:25Z", "--query-timeout", "5s", "--lookback-delta", "10m"},
+			expected: "[OK] - states: ok=1\n\\_ [OK]  {lookback_delta=\"10m0s\", time=\"1696589905\", timeout=\"5s\"} - value: 1\n",
+		},
+		{
+			name: "vector-time-invalid",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up", "--time", "yesterday"},
+			expected: "[UNKNOWN] - invalid value for --time: invalid time 'yesterday', must be a RFC3339 or unix timestamp or relative to now e.g. -2m",
+		},
+		{
+			name: "query-library",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"mountpoint":"/var","query":%q},"value":[1696589905,"85"]}]}}`, r.FormValue("query"))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query-library", "../testdata/unittest/queryLibrary.yaml", "--query-name", "disk_usage", "--var", "instance=db\"01"},
+			expected: "[WARNING] - Disk usage of db\"01 - states: warning=1\n\\_ [WARNING]  {mountpoint=\"/var\", query=\"disk_used_percent{instance=\\\"db\\\\\\\"01\\\"}\"} - value: 85\n|/var=85;80;90\n\nexit status 1\n",
+		},
+		{
+			name: "query-library-threshold-override",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"mountpoint":"/var"},"value":[1696589905,"85"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query-library", "../testdata/unittest/queryLibrary.yaml", "--query-name", "disk_usage", "--var", "instance=db01", "-w", "95", "-c", "99"},
+			expected: "[OK] - Disk usage of db01 - states: ok=1\n\\_ [OK]  {mountpoint=\"/var\"} - value: 85\n|/var=85;95;99\n\n",
+		},
+		{
+			name: "query-library-unresolved",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"query":%q},"value":[1696589905,"1"]}]}}`, r.FormValue("query"))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query-library", "../testdata/unittest/queryLibrary.yaml", "--query-name", "up"},
+			expected: "[OK] - Targets of ${job} - states: ok=1\n\\_ [OK]  {query=\"up{job=\\\"${job}\\\"}\"} - value: 1\n",
+		},
+		{
+			name: "query-library-unknown-query",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query-library", "../testdata/unittest/queryLibrary.yaml", "--query-name", "load"},
+			expected: "[UNKNOWN] - query 'load' not found in query library, available queries: disk_usage, up",
+		},
+		{
+			name: "query-vars",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"query":%q},"value":[1696589905,"1"]}]}}`, r.FormValue("query"))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", `up{service="${service}", host=~"${host:regex}"}`, "--var", `service=check "disk" C:\`, "--var", "host=web.(1)"},
+			expected: "[OK] - states: ok=1\n\\_ [OK]  {query=\"up{service=\\\"check \\\\\\\"disk\\\\\\\" C:\\\\\\\\\\\", host=~\\\"web\\\\\\\\.\\\\\\\\(1\\\\\\\\)\\\"}\"} - value: 1\n",
+		},
+		{
+			name: "query-vars-label-replace",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"query":%q},"value":[1696589905,"1"]}]}}`, r.FormValue("query"))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", `label_replace(up, "host", "${1}", "instance", "(.*):.*")`},
+			expected: "[OK] - states: ok=1\n\\_ [OK]  {query=\"label_replace(up, \\\"host\\\", \\\"${1}\\\", \\\"instance\\\", \\\"(.*):.*\\\")\"} - value: 1\n",
+		},
+		{
+			name: "query-vars-label-replace-with-vars",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"query":%q},"value":[1696589905,"1"]}]}}`, r.FormValue("query"))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", `label_replace(up{job="${job}"}, "host", "${1}", "instance", "(.*):.*")`, "--var", "job=node"},
+			expected: "[OK] - states: ok=1\n\\_ [OK]  {query=\"label_replace(up{job=\\\"node\\\"}, \\\"host\\\", \\\"${1}\\\", \\\"instance\\\", \\\"(.*):.*\\\")\"} - value: 1\n",
+		},
+		{
+			name: "ratio-vars",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				if r.FormValue("query") == `errors{job="api \"v2\""}` {
+					w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"api"},"value":[1696589905.608,"1"]}]}}`))
+					return
+				}
+				if r.FormValue("query") == `requests{job="api \"v2\""}` {
+					w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"api"},"value":[1696589905.608,"100"]}]}}`))
+					return
+				}
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--numerator", `errors{job="${job}"}`, "--denominator", `requests{job="${job}"}`, "--var", `job=api "v2"`},
+			expected: "[OK] - states: ok=1\n\\_ [OK]  ratio{job=\"api\"} - value: 0.01 (1 / 100)\n",
+		},
+		{
+			name: "ratio-vars-invalid",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--numerator", "errors", "--denominator", `requests{job="${job:json}"}`, "--var", "job=api"},
+			expected: "[UNKNOWN] - invalid placeholders in query, must be ${name} or ${name:regex}: ${job:json}",
+		},
+		{
+			name: "histogram-vars",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				if r.FormValue("query") != `sum by (le) (rate(http_request_duration_seconds_bucket{job=~"api\\.v2"}[5m]))` {
+					w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+					return
+				}
+				w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"le":"1"},"value":[1696589905.608,"100"]},{"metric":{"le":"+Inf"},"value":[1696589905.608,"100"]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--histogram", `http_request_duration_seconds{job=~"${job:regex}"}`, "--var", "job=api.v2"},
+			expected: "[OK] - states: ok=1\n\\_ [OK]  http_request_duration_seconds{quantile=\"0.95\"} - value: 0.95\n",
+		},
+		{
+			name: "mode-compare-forecast",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up", "--compare-offset", "1d", "--forecast-target", "10"},
+			expected: "[UNKNOWN] - --compare-offset can't be combined with --forecast-target",
+		},
+		{
+			name: "mode-forecast-duty-cycle",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up", "--forecast-target", "10", "--duty-cycle", "1h", "--critical-duty-cycle", "50"},
+			expected: "[UNKNOWN] - --forecast-target can't be combined with --duty-cycle",
+		},
+		{
+			name: "mode-duty-cycle-value-map",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up", "--duty-cycle", "1h", "--critical-duty-cycle", "50", "--value-map", "0=CRITICAL,1=OK"},
+			expected: "[UNKNOWN] - --duty-cycle can't be combined with --value-map",
+		},
+		{
+			name: "mode-numerator-aggregate",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--numerator", "a", "--denominator", "b", "--aggregate", "max"},
+			expected: "[UNKNOWN] - --numerator can't be combined with --aggregate",
+		},
+		{
+			name: "mode-numerator-value-map",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--numerator", "a", "--denominator", "b", "--value-map", "0=CRITICAL"},
+			expected: "[UNKNOWN] - --numerator can't be combined with --value-map",
+		},
+		{
+			name: "mode-numerator-expect-string",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--numerator", "a", "--denominator", "b", "--expect-string", "^v2"},
+			expected: "[UNKNOWN] - --numerator can't be combined with --expect-string",
+		},
+		{
+			name: "mode-histogram-aggregate",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--histogram", "c", "--aggregate", "max"},
+			expected: "[UNKNOWN] - --histogram can't be combined with --aggregate",
+		},
+		{
+			name: "mode-histogram-value-map",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--histogram", "c", "--value-map", "0=CRITICAL"},
+			expected: "[UNKNOWN] - --histogram can't be combined with --value-map",
+		},
+		{
+			name: "mode-histogram-expect-string",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--histogram", "c", "--expect-string", "^v2"},
+			expected: "[UNKNOWN] - --histogram can't be combined with --expect-string",
+		},
+		{
+			name: "mode-forecast-aggregate",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up", "--forecast-target", "10", "--aggregate", "max"},
+			expected: "[UNKNOWN] - --forecast-target can't be combined with --aggregate",
+		},
+		{
+			name: "mode-forecast-value-map",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up", "--forecast-target", "10", "--value-map", "0=CRITICAL"},
+			expected: "[UNKNOWN] - --forecast-target can't be combined with --value-map",
+		},
+		{
+			name: "mode-forecast-expect-string",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up", "--forecast-target", "10", "--expect-string", "^v2"},
+			expected: "[UNKNOWN] - --forecast-target can't be combined with --expect-string",
+		},
+		{
+			name: "matrix-compare-offset",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"instance":"a"},"values":[[1696589905.608,"1"]]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up[5m]", "--compare-offset", "1d"},
+			expected: "[UNKNOWN] - matrix value results are not supported with --compare-offset, expected a vector",
+		},
+		{
+			name: "mode-numerator-histogram-compare",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--numerator", "a", "--denominator", "b", "--histogram", "c", "--compare-offset", "1d"},
+			expected: "[UNKNOWN] - --numerator can't be combined with --histogram, --compare-offset",
+		},
+		{
+			name: "matrix-aggregate-max",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"up","instance":"localhost:9100","job":"node"},"values":[[1696589212.987,"1"],[1696589272.987,"12"],[1696589332.987,"1"]]},{"metric":{"__name__":"up","instance":"localhost:9104","job":"mysqld"},"values":[[1696589209.089,"2"],[1696589269.089,"4"],[1696589329.089,"3"]]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up[5m]", "-w", "10", "-c", "20", "--aggregate", "max"},
+			expected: "[WARNING] - states: warning=1 ok=1\n\\_ [WARNING]  up{instance=\"localhost:9100\", job=\"node\"} - max: 12\n\\_ [OK]  up{instance=\"localhost:9104\", job=\"mysqld\"} - max: 4\n|up_instance_localhost:9100_job_node_max=12;10;20 up_instance_localhost:9104_job_mysqld_max=4;10;20\n\nexit status 1\n",
+		},
+		{
+			name: "matrix-aggregate-percentile",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"up","job":"node"},"values":[[1696589212.987,"1"],[1696589272.987,"2"],[1696589332.987,"3"],[1696589392.987,"4"],[1696589452.987,"5"]]}]}}`))
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up[5m]", "-w", "10", "-c", "20", "--aggregate", "percentile", "--percentile", "50"},
+			expected: "[OK] - states: ok=1\n\\_ [OK]  up{job=\"node\"} - p50: 3\n|up_job_node_p50=3;10;20\n\n",
+		},
+		{
+			name: "matrix-aggregate-invalid",
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			args:     []string{"run", "../main.go", "query", "--query", "up[5m]", "--aggregate", "median"},
+			expected: "[UNKNOWN] - invalid aggregation 'median'",
+		},
 	}
 
 	for _, test := range tests {
@@ -6,7 +6,10 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
+	"github.com/NETWAYS/check_prometheus/internal/output"
 	"github.com/NETWAYS/go-check"
 	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
@@ -108,6 +111,21 @@ func (a *Rule) GetStatus(labelKey string) (status int) {
 }
 
 func (a *Rule) GetOutput() (output string) {
+	return a.formatOutput(func(v string) string {
+		value, _ := strconv.ParseFloat(v, 32)
+		return fmt.Sprintf("%.2f", value)
+	})
+}
+
+// GetHumanizedOutput returns the same output as GetOutput, with the value humanized with the given mode
+func (a *Rule) GetHumanizedOutput(mode string) string {
+	return a.formatOutput(func(v string) string {
+		value, _ := strconv.ParseFloat(v, 64)
+		return output.HumanizeMode(mode, value, time.Now())
+	})
+}
+
+func (a *Rule) formatOutput(formatValue func(string) string) string {
 	if a.Alert == nil {
 		return fmt.Sprintf("[%s] is %s",
 			a.AlertingRule.Name,
@@ -115,10 +133,9 @@ func (a *Rule) GetOutput() (output string) {
 	}
 
 	var (
-		value float64
-		v     model.LabelValue
-		ok    bool
-		out   strings.Builder
+		v   model.LabelValue
+		ok  bool
+		out strings.Builder
 	)
 
 	// Base Output
@@ -137,8 +154,7 @@ func (a *Rule) GetOutput() (output string) {
 	}
 
 	// Add current value to output
-	value, _ = strconv.ParseFloat(a.Alert.Value, 32)
-	fmt.Fprintf(&out, " is %s - value: %.2f", a.AlertingRule.State, value)
+	fmt.Fprintf(&out, " is %s - value: %s", a.AlertingRule.State, formatValue(a.Alert.Value))
 	// Add labels to the output
 	l, err := json.Marshal(a.Alert.Labels)
 
@@ -149,3 +165,26 @@ func (a *Rule) GetOutput() (output string) {
 
 	return out.String()
 }
+
+// GetTemplateOutput renders the output template with the labels, annotations,
+// value and active time of the alert and the given status
+func (a *Rule) GetTemplateOutput(tmpl *template.Template, status int) (string, error) {
+	data := output.Data{
+		Name:        a.AlertingRule.Name,
+		Labels:      output.LabelMap(a.AlertingRule.Labels),
+		Annotations: output.LabelMap(a.AlertingRule.Annotations),
+		State:       check.StatusText(status),
+		AlertState:  a.AlertingRule.State,
+	}
+
+	if a.Alert != nil {
+		data.Labels = output.LabelMap(a.Alert.Labels)
+		data.Annotations = output.LabelMap(a.Alert.Annotations)
+		data.AlertState = string(a.Alert.State)
+		data.Value, _ = strconv.ParseFloat(a.Alert.Value, 64)
+		data.ActiveAt = a.Alert.ActiveAt
+		data.ActiveSince = time.Since(a.Alert.ActiveAt).Round(time.Second)
+	}
+
+	return output.Render(tmpl, data)
+}
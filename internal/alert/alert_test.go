@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/NETWAYS/check_prometheus/internal/output"
 	"github.com/NETWAYS/go-check"
 	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
@@ -180,6 +181,78 @@ func TestGetOutput(t *testing.T) {
 	}
 }
 
+func TestGetHumanizedOutput(t *testing.T) {
+	r := Rule{
+		AlertingRule: v1.AlertingRule{
+			Name:  "CertificateExpiry",
+			State: "firing",
+		},
+		Alert: &v1.Alert{
+			Labels: model.LabelSet{
+				"alertname": "CertificateExpiry",
+			},
+			State: v1.AlertStateFiring,
+			Value: "-6065338",
+		},
+	}
+
+	expected := `[CertificateExpiry] is firing - value: -6.065M - {"alertname":"CertificateExpiry"}`
+	if actual := r.GetHumanizedOutput(output.ModeSI); actual != expected {
+		t.Error("\nActual: ", actual, "\nExpected: ", expected)
+	}
+
+	r.Alert.Value = "1.073741824e+09"
+
+	expected = `[CertificateExpiry] is firing - value: 1GiB - {"alertname":"CertificateExpiry"}`
+	if actual := r.GetHumanizedOutput(output.ModeBytes); actual != expected {
+		t.Error("\nActual: ", actual, "\nExpected: ", expected)
+	}
+}
+
+func TestGetTemplateOutput(t *testing.T) {
+	ar := v1.AlertingRule{
+		Name:  "DiskFull",
+		State: "firing",
+		Annotations: model.LabelSet{
+			"summary": "Disk {{ $labels.mountpoint }} is full",
+		},
+		Labels: model.LabelSet{
+			"severity": "critical",
+		},
+	}
+
+	r := Rule{AlertingRule: ar}
+
+	tmpl, err := output.NewTemplate("test", `{{.Name}} is {{.AlertState}} ({{.State}}){{with .Labels.mountpoint}} on {{.}}{{end}} - {{.Annotations.description}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "DiskFull is firing (CRITICAL) - "
+	if actual, _ := r.GetTemplateOutput(tmpl, check.Critical); actual != expected {
+		t.Error("\nActual: ", actual, "\nExpected: ", expected)
+	}
+
+	r.Alert = &v1.Alert{
+		ActiveAt: time.Now().Add(-90 * time.Second),
+		Annotations: model.LabelSet{
+			"description": "Disk /var is 93% full",
+		},
+		Labels: model.LabelSet{
+			"mountpoint": "/var",
+		},
+		State: v1.AlertStatePending,
+		Value: "0.93",
+	}
+
+	tmpl, _ = output.NewTemplate("test", `{{.Name}} is {{.AlertState}} on {{.Labels.mountpoint}} since {{humanizeDuration .ActiveSince}} - {{humanizePercentage .Value}} - {{.Annotations.description}}`)
+
+	expected = "DiskFull is pending on /var since 1m 30s - 93% - Disk /var is 93% full"
+	if actual, _ := r.GetTemplateOutput(tmpl, check.Warning); actual != expected {
+		t.Error("\nActual: ", actual, "\nExpected: ", expected)
+	}
+}
+
 func TestFlattenRules(t *testing.T) {
 	testTime := time.Now()
 
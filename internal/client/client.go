@@ -36,7 +36,7 @@ func (c *Client) Connect() error {
 		return fmt.Errorf("error creating client: %w", err)
 	}
 
-	c.Client = cfg
+	c.Client = &recordingClient{cfg}
 	c.API = v1.NewAPI(c.Client)
 
 	return nil
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/api"
+	"github.com/prometheus/common/model"
+)
+
+type responseKey struct{}
+
+type annotationsKey struct{}
+
+// Response holds the raw body of a Prometheus API response.
+// The v1.API only decodes parts of a response (e.g. no string results),
+// the Response can be used to access the remaining data.
+type Response struct {
+	Body []byte
+}
+
+// queryResponse is the raw representation of a query API response
+type queryResponse struct {
+	Data struct {
+		ResultType model.ValueType `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+		Stats      *QueryStats     `json:"stats"`
+	} `json:"data"`
+}
+
+// QueryStats are the statistics of a query requested with stats=all.
+// The timings are in seconds.
+type QueryStats struct {
+	Timings struct {
+		EvalTotalTime        float64 `json:"evalTotalTime"`
+		ResultSortTime       float64 `json:"resultSortTime"`
+		QueryPreparationTime float64 `json:"queryPreparationTime"`
+		InnerEvalTime        float64 `json:"innerEvalTime"`
+		ExecQueueTime        float64 `json:"execQueueTime"`
+		ExecTotalTime        float64 `json:"execTotalTime"`
+	} `json:"timings"`
+	Samples struct {
+		TotalQueryableSamples int64 `json:"totalQueryableSamples"`
+		PeakSamples           int64 `json:"peakSamples"`
+	} `json:"samples"`
+}
+
+// WithResponse returns a context that stores the body of the API response in r
+func WithResponse(ctx context.Context, r *Response) context.Context {
+	return context.WithValue(ctx, responseKey{}, r)
+}
+
+// StringResult decodes a string result from a query API response
+func (r *Response) StringResult() (*model.String, error) {
+	var qr queryResponse
+
+	if err := json.Unmarshal(r.Body, &qr); err != nil {
+		return nil, err
+	}
+
+	if qr.Data.ResultType != model.ValString {
+		return nil, errors.New("response does not contain a string result")
+	}
+
+	var s model.String
+
+	if err := json.Unmarshal(qr.Data.Result, &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// Stats decodes the statistics from a query API response.
+// The v1.API drops the statistics when decoding the result.
+func (r *Response) Stats() (*QueryStats, error) {
+	var qr queryResponse
+
+	if err := json.Unmarshal(r.Body, &qr); err != nil {
+		return nil, err
+	}
+
+	if qr.Data.Stats == nil {
+		return nil, errors.New("response does not contain query statistics")
+	}
+
+	return qr.Data.Stats, nil
+}
+
+// Annotations collects the info annotations of all API responses made with a context.
+// The v1.API only returns the warnings of a response.
+type Annotations struct {
+	mu    sync.Mutex
+	infos []string
+}
+
+// annotationsResponse is the raw representation of the annotations of an API response
+type annotationsResponse struct {
+	Infos []string `json:"infos"`
+}
+
+// WithAnnotations returns a context that collects the info annotations of the API responses in a
+func WithAnnotations(ctx context.Context, a *Annotations) context.Context {
+	return context.WithValue(ctx, annotationsKey{}, a)
+}
+
+// Infos returns the collected info annotations
+func (a *Annotations) Infos() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.infos
+}
+
+func (a *Annotations) add(body []byte) {
+	var ar annotationsResponse
+
+	// Responses without annotations are no error
+	if err := json.Unmarshal(body, &ar); err != nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.infos = append(a.infos, ar.Infos...)
+}
+
+// recordingClient stores the response body in the Response of the request's context
+// and collects the info annotations in the Annotations of the request's context
+type recordingClient struct {
+	api.Client
+}
+
+func (c *recordingClient) Do(ctx context.Context, req *http.Request) (*http.Response, []byte, error) {
+	resp, body, err := c.Client.Do(ctx, req)
+
+	if r, ok := ctx.Value(responseKey{}).(*Response); ok {
+		r.Body = body
+	}
+
+	if a, ok := ctx.Value(annotationsKey{}).(*Annotations); ok {
+		a.add(body)
+	}
+
+	return resp, body, err
+}
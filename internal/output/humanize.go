@@ -0,0 +1,214 @@
+package output
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Modes to humanize values in the plugin output
+const (
+	ModeBytes     = "bytes"
+	ModeSI        = "si"
+	ModeDuration  = "duration"
+	ModeTimestamp = "timestamp"
+)
+
+// Modes contains all supported humanize modes
+var Modes = []string{ModeBytes, ModeSI, ModeDuration, ModeTimestamp}
+
+// ValidateMode returns an error if the given humanize mode is not supported
+func ValidateMode(mode string) error {
+	if !slices.Contains(Modes, mode) {
+		return fmt.Errorf("invalid humanize mode '%s', must be one of: %s", mode, strings.Join(Modes, ", "))
+	}
+
+	return nil
+}
+
+// HumanizeMode formats a value with the given mode:
+// bytes with binary prefixes, SI prefixes, a duration in seconds or a unix timestamp relative to now
+func HumanizeMode(mode string, v float64, now time.Time) string {
+	switch mode {
+	case ModeBytes:
+		return Humanize1024(v) + "B"
+	case ModeSI:
+		return Humanize(v)
+	case ModeDuration:
+		return HumanizeDuration(v)
+	case ModeTimestamp:
+		return HumanizeRelative(v, now)
+	}
+
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// Humanize formats a value with SI prefixes, e.g. 1.234k for 1234 or 5m for 0.005.
+// Same as humanize in Prometheus templates.
+func Humanize(v float64) string {
+	if v == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Sprintf("%.4g", v)
+	}
+
+	if math.Abs(v) >= 1 {
+		prefix := ""
+
+		for _, p := range []string{"k", "M", "G", "T", "P", "E", "Z", "Y"} {
+			if math.Abs(v) < 1000 {
+				break
+			}
+
+			prefix = p
+			v /= 1000
+		}
+
+		return fmt.Sprintf("%.4g%s", v, prefix)
+	}
+
+	prefix := ""
+
+	for _, p := range []string{"m", "u", "n", "p", "f", "a", "z", "y"} {
+		if math.Abs(v) >= 1 {
+			break
+		}
+
+		prefix = p
+		v *= 1000
+	}
+
+	return fmt.Sprintf("%.4g%s", v, prefix)
+}
+
+// Humanize1024 formats a value with binary prefixes, e.g. 1.5Mi for 1572864.
+// Same as humanize1024 in Prometheus templates.
+func Humanize1024(v float64) string {
+	if math.Abs(v) <= 1 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Sprintf("%.4g", v)
+	}
+
+	prefix := ""
+
+	for _, p := range []string{"ki", "Mi", "Gi", "Ti", "Pi", "Ei", "Zi", "Yi"} {
+		if math.Abs(v) < 1024 {
+			break
+		}
+
+		prefix = p
+		v /= 1024
+	}
+
+	return fmt.Sprintf("%.4g%s", v, prefix)
+}
+
+// HumanizeDuration formats a number of seconds as duration, e.g. 1d 2h 3m 4s or 250ms.
+// Same as humanizeDuration in Prometheus templates.
+func HumanizeDuration(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Sprintf("%.4g", v)
+	}
+
+	if v == 0 {
+		return fmt.Sprintf("%.4gs", v)
+	}
+
+	if math.Abs(v) >= 1 {
+		sign := ""
+		if v < 0 {
+			sign = "-"
+			v = -v
+		}
+
+		duration := int64(v)
+		seconds := duration % 60
+		minutes := (duration / 60) % 60
+		hours := (duration / 60 / 60) % 24
+		days := duration / 60 / 60 / 24
+
+		// For days to minutes, the seconds are displayed as an integer
+		switch {
+		case days != 0:
+			return fmt.Sprintf("%s%dd %dh %dm %ds", sign, days, hours, minutes, seconds)
+		case hours != 0:
+			return fmt.Sprintf("%s%dh %dm %ds", sign, hours, minutes, seconds)
+		case minutes != 0:
+			return fmt.Sprintf("%s%dm %ds", sign, minutes, seconds)
+		}
+
+		return fmt.Sprintf("%s%.4gs", sign, v)
+	}
+
+	prefix := ""
+
+	for _, p := range []string{"m", "u", "n", "p", "f", "a", "z", "y"} {
+		if math.Abs(v) >= 1 {
+			break
+		}
+
+		prefix = p
+		v *= 1000
+	}
+
+	return fmt.Sprintf("%.4g%ss", v, prefix)
+}
+
+// HumanizePercentage formats a ratio as percentage, e.g. 93.5% for 0.935.
+// Same as humanizePercentage in Prometheus templates.
+func HumanizePercentage(v float64) string {
+	return fmt.Sprintf("%.4g%%", v*100)
+}
+
+// HumanizeTimestamp formats a unix timestamp as UTC time, e.g. 2022-11-21 10:38:35 +0000 UTC.
+// Same as humanizeTimestamp in Prometheus templates.
+func HumanizeTimestamp(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Sprintf("%.4g", v)
+	}
+
+	return fmt.Sprint(unixTime(v).UTC())
+}
+
+// HumanizeRelative formats a unix timestamp relative to now, e.g. 5m 0s ago or in 1h 0m 0s
+func HumanizeRelative(v float64, now time.Time) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Sprintf("%.4g", v)
+	}
+
+	d := now.Sub(unixTime(v)).Round(time.Second).Seconds()
+	if d < 0 {
+		return "in " + HumanizeDuration(-d)
+	}
+
+	return HumanizeDuration(d) + " ago"
+}
+
+func unixTime(v float64) time.Time {
+	seconds, fraction := math.Modf(v)
+
+	return time.Unix(int64(seconds), int64(fraction*1e9))
+}
+
+// toFloat64 converts the argument of a template function to a float64,
+// so that the functions can be used with values as well as labels
+func toFloat64(v any) (float64, error) {
+	switch value := v.(type) {
+	case float64:
+		return value, nil
+	case float32:
+		return float64(value), nil
+	case int:
+		return float64(value), nil
+	case int64:
+		return float64(value), nil
+	case time.Duration:
+		return value.Seconds(), nil
+	case string:
+		return strconv.ParseFloat(value, 64)
+	case fmt.Stringer:
+		return strconv.ParseFloat(value.String(), 64)
+	}
+
+	return 0, fmt.Errorf("can't convert %v (%T) to a number", v, v)
+}
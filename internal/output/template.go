@@ -0,0 +1,70 @@
+package output
+
+import (
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Data contains the fields available in output templates
+type Data struct {
+	// Name of the metric or alert
+	Name        string
+	Labels      map[string]string
+	Annotations map[string]string
+	Value       float64
+	// State of the check, e.g. CRITICAL
+	State string
+	// State of the alert, e.g. firing
+	AlertState  string
+	ActiveAt    time.Time
+	ActiveSince time.Duration
+}
+
+// Funcs are the helper functions available in output templates
+var Funcs = template.FuncMap{
+	"humanize":           wrap(Humanize),
+	"humanize1024":       wrap(Humanize1024),
+	"humanizeDuration":   wrap(HumanizeDuration),
+	"humanizePercentage": wrap(HumanizePercentage),
+	"humanizeTimestamp":  wrap(HumanizeTimestamp),
+}
+
+// NewTemplate parses an output template, missing labels and annotations are replaced by an empty string
+func NewTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=zero").Funcs(Funcs).Parse(text)
+}
+
+// Render executes the template with the given data
+func Render(tmpl *template.Template, data Data) (string, error) {
+	var sb strings.Builder
+
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+// wrap turns a formatting function into a template function that accepts values as well as labels
+func wrap(fn func(float64) string) func(any) (string, error) {
+	return func(v any) (string, error) {
+		f, err := toFloat64(v)
+		if err != nil {
+			return "", err
+		}
+
+		return fn(f), nil
+	}
+}
+
+// LabelMap converts a set of labels or annotations to a map usable in templates
+func LabelMap[K ~string, V ~string](labels map[K]V) map[string]string {
+	m := make(map[string]string, len(labels))
+
+	for k, v := range labels {
+		m[string(k)] = string(v)
+	}
+
+	return m
+}
@@ -0,0 +1,90 @@
+package output
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHumanize(t *testing.T) {
+	testcases := []struct {
+		humanize func(float64) string
+		in       float64
+		want     string
+	}{
+		{Humanize1024, 0.5, "0.5"},
+		{Humanize1024, 1572864, "1.5Mi"},
+		{HumanizeDuration, 0, "0s"},
+		{HumanizeDuration, 0.25, "250ms"},
+		{HumanizeDuration, 42, "42s"},
+		{HumanizeDuration, 93784, "1d 2h 3m 4s"},
+		{HumanizeDuration, -3600, "-1h 0m 0s"},
+		{HumanizePercentage, 0.935, "93.5%"},
+		{Humanize, 1234567, "1.235M"},
+		{Humanize, 0.005, "5m"},
+		{HumanizeTimestamp, 1669027115, "2022-11-21 10:38:35 +0000 UTC"},
+	}
+
+	for _, tc := range testcases {
+		actual := tc.humanize(tc.in)
+		if actual != tc.want {
+			t.Error("\nActual: ", actual, "\nExpected: ", tc.want, "\nInput: ", tc.in)
+		}
+	}
+}
+
+func TestHumanizeMode(t *testing.T) {
+	now := time.Unix(1669027115, 0)
+
+	testcases := []struct {
+		mode string
+		in   float64
+		want string
+	}{
+		{ModeBytes, 1073741824, "1GiB"},
+		{ModeSI, -6065338, "-6.065M"},
+		{ModeDuration, 3600, "1h 0m 0s"},
+		{ModeTimestamp, 1669026815, "5m 0s ago"},
+		{ModeTimestamp, 1669030715, "in 1h 0m 0s"},
+		{"", 1.5, "1.5"},
+	}
+
+	for _, tc := range testcases {
+		actual := HumanizeMode(tc.mode, tc.in, now)
+		if actual != tc.want {
+			t.Error("\nActual: ", actual, "\nExpected: ", tc.want, "\nMode: ", tc.mode)
+		}
+	}
+
+	if ValidateMode("percent") == nil {
+		t.Error("Expected error for invalid mode")
+	}
+}
+
+func TestRender(t *testing.T) {
+	tmpl, err := NewTemplate("test", `Disk {{.Labels.mountpoint}} on {{.Labels.instance}}{{.Labels.missing}} is {{humanizePercentage .Value}} full ({{.State}}, {{humanize1024 .Labels.size}}B, {{humanizeDuration .ActiveSince}})`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := Data{
+		Labels:      map[string]string{"mountpoint": "/var", "instance": "db01", "size": "1073741824"},
+		Value:       0.93,
+		State:       "CRITICAL",
+		ActiveSince: 90 * time.Second,
+	}
+
+	actual, err := Render(tmpl, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Disk /var on db01 is 93% full (CRITICAL, 1GiB, 1m 30s)"
+	if actual != expected {
+		t.Error("\nActual: ", actual, "\nExpected: ", expected)
+	}
+
+	tmpl, _ = NewTemplate("test", `{{humanize1024 .Labels.instance}}`)
+	if _, err := Render(tmpl, data); err == nil {
+		t.Error("Expected error for a label that is not a number")
+	}
+}
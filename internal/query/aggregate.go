@@ -0,0 +1,172 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+// Aggregation functions that can be used to reduce the values of a range vector
+const (
+	AggregateLast       = "last"
+	AggregateFirst      = "first"
+	AggregateMin        = "min"
+	AggregateMax        = "max"
+	AggregateAvg        = "avg"
+	AggregateSum        = "sum"
+	AggregateStddev     = "stddev"
+	AggregatePercentile = "percentile"
+	AggregateDelta      = "delta"
+	AggregateRate       = "rate"
+)
+
+// Aggregations contains all supported aggregation functions
+var Aggregations = []string{
+	AggregateLast,
+	AggregateFirst,
+	AggregateMin,
+	AggregateMax,
+	AggregateAvg,
+	AggregateSum,
+	AggregateStddev,
+	AggregatePercentile,
+	AggregateDelta,
+	AggregateRate,
+}
+
+// ValidateAggregation returns an error if the given aggregation function is not supported
+func ValidateAggregation(fn string) error {
+	if !slices.Contains(Aggregations, fn) {
+		return fmt.Errorf("invalid aggregation '%s', must be one of: %s", fn, strings.Join(Aggregations, ", "))
+	}
+
+	return nil
+}
+
+// AggregationLabel returns the name of the aggregation used in outputs and perfdata labels,
+// e.g. 'p95' for the 95th percentile.
+func AggregationLabel(fn string, percentile float64) string {
+	if fn == AggregatePercentile {
+		return "p" + strings.ReplaceAll(fmt.Sprint(percentile), ".", "_")
+	}
+
+	return fn
+}
+
+// Aggregate reduces the values of a SampleStream to a single value using the given function.
+// The percentile is only used by the percentile function and has to be within 0 and 100.
+func Aggregate(fn string, values []model.SamplePair, percentile float64) (float64, error) {
+	if len(values) == 0 {
+		return 0, errors.New("no samples to aggregate")
+	}
+
+	first := float64(values[0].Value)
+	last := float64(values[len(values)-1].Value)
+
+	switch fn {
+	case AggregateLast:
+		return last, nil
+	case AggregateFirst:
+		return first, nil
+	case AggregateMin:
+		minimum := math.Inf(1)
+		for _, v := range values {
+			minimum = math.Min(minimum, float64(v.Value))
+		}
+
+		return minimum, nil
+	case AggregateMax:
+		maximum := math.Inf(-1)
+		for _, v := range values {
+			maximum = math.Max(maximum, float64(v.Value))
+		}
+
+		return maximum, nil
+	case AggregateSum:
+		return sum(values), nil
+	case AggregateAvg:
+		return sum(values) / float64(len(values)), nil
+	case AggregateStddev:
+		// Population standard deviation, same as stddev_over_time()
+		avg := sum(values) / float64(len(values))
+
+		var variance float64
+		for _, v := range values {
+			variance += math.Pow(float64(v.Value)-avg, 2)
+		}
+
+		return math.Sqrt(variance / float64(len(values))), nil
+	case AggregatePercentile:
+		return quantile(percentile/100, values)
+	case AggregateDelta:
+		return last - first, nil
+	case AggregateRate:
+		return rate(values)
+	}
+
+	return 0, fmt.Errorf("invalid aggregation '%s'", fn)
+}
+
+func sum(values []model.SamplePair) float64 {
+	var s float64
+	for _, v := range values {
+		s += float64(v.Value)
+	}
+
+	return s
+}
+
+// quantile calculates the φ-quantile of the values
+// using linear interpolation, same as quantile_over_time().
+func quantile(q float64, values []model.SamplePair) (float64, error) {
+	if q < 0 || q > 1 {
+		return 0, fmt.Errorf("percentile must be between 0 and 100, got %v", q*100)
+	}
+
+	sorted := make([]float64, 0, len(values))
+	for _, v := range values {
+		sorted = append(sorted, float64(v.Value))
+	}
+
+	slices.Sort(sorted)
+
+	rank := q * float64(len(sorted)-1)
+	lower := math.Floor(rank)
+	upper := math.Ceil(rank)
+	weight := rank - lower
+
+	return sorted[int(lower)]*(1-weight) + sorted[int(upper)]*weight, nil
+}
+
+// rate calculates the per-second increase of a counter,
+// counter resets are taken into account.
+func rate(values []model.SamplePair) (float64, error) {
+	if len(values) < 2 {
+		return 0, errors.New("rate requires at least two samples")
+	}
+
+	var increase float64
+
+	for i := 1; i < len(values); i++ {
+		current := float64(values[i].Value)
+		previous := float64(values[i-1].Value)
+
+		if current < previous {
+			// Counter reset, the counter started again from zero
+			increase += current
+		} else {
+			increase += current - previous
+		}
+	}
+
+	duration := values[len(values)-1].Timestamp.Sub(values[0].Timestamp).Seconds()
+	if duration <= 0 {
+		return 0, errors.New("rate requires samples with different timestamps")
+	}
+
+	return increase / duration, nil
+}
@@ -0,0 +1,114 @@
+package query
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestAggregate(t *testing.T) {
+	values := []model.SamplePair{
+		{Timestamp: 1000, Value: 4},
+		{Timestamp: 61000, Value: 2},
+		{Timestamp: 121000, Value: 8},
+		{Timestamp: 181000, Value: 6},
+	}
+
+	testcases := map[string]float64{
+		AggregateLast:   6,
+		AggregateFirst:  4,
+		AggregateMin:    2,
+		AggregateMax:    8,
+		AggregateSum:    20,
+		AggregateAvg:    5,
+		AggregateStddev: math.Sqrt(5),
+		AggregateDelta:  2,
+	}
+
+	for fn, expected := range testcases {
+		actual, err := Aggregate(fn, values, 0)
+		if err != nil {
+			t.Error(fn, err)
+		}
+
+		if actual != expected {
+			t.Error(fn, "\nActual: ", actual, "\nExpected: ", expected)
+		}
+	}
+}
+
+func TestAggregate_Percentile(t *testing.T) {
+	values := []model.SamplePair{
+		{Timestamp: 1000, Value: 1},
+		{Timestamp: 2000, Value: 2},
+		{Timestamp: 3000, Value: 3},
+		{Timestamp: 4000, Value: 4},
+		{Timestamp: 5000, Value: 5},
+	}
+
+	actual, _ := Aggregate(AggregatePercentile, values, 50)
+	if actual != 3 {
+		t.Error("\nActual: ", actual, "\nExpected: ", 3)
+	}
+
+	actual, _ = Aggregate(AggregatePercentile, values, 90)
+	if math.Abs(actual-4.6) > 1e-9 {
+		t.Error("\nActual: ", actual, "\nExpected: ", 4.6)
+	}
+
+	_, err := Aggregate(AggregatePercentile, values, 101)
+	if err == nil {
+		t.Error("Expected error for invalid percentile")
+	}
+}
+
+func TestAggregate_Rate(t *testing.T) {
+	// Counter with a reset between the second and third sample
+	values := []model.SamplePair{
+		{Timestamp: 0, Value: 10},
+		{Timestamp: 10000, Value: 20},
+		{Timestamp: 20000, Value: 5},
+		{Timestamp: 25000, Value: 15},
+	}
+
+	actual, err := Aggregate(AggregateRate, values, 0)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if actual != 1 {
+		t.Error("\nActual: ", actual, "\nExpected: ", 1)
+	}
+
+	_, err = Aggregate(AggregateRate, values[:1], 0)
+	if err == nil {
+		t.Error("Expected error for a single sample")
+	}
+}
+
+func TestAggregate_Invalid(t *testing.T) {
+	_, err := Aggregate("median", []model.SamplePair{{Value: 1}}, 0)
+	if err == nil {
+		t.Error("Expected error for invalid aggregation")
+	}
+
+	_, err = Aggregate(AggregateLast, nil, 0)
+	if err == nil {
+		t.Error("Expected error for empty samples")
+	}
+
+	if ValidateAggregation("median") == nil {
+		t.Error("Expected error for invalid aggregation")
+	}
+}
+
+func TestAggregationLabel(t *testing.T) {
+	if AggregationLabel(AggregateAvg, 95) != "avg" {
+		t.Error("\nActual: ", AggregationLabel(AggregateAvg, 95), "\nExpected: avg")
+	}
+
+	if AggregationLabel(AggregatePercentile, 99.9) != "p99_9" {
+		t.Error("\nActual: ", AggregationLabel(AggregatePercentile, 99.9), "\nExpected: p99_9")
+	}
+}
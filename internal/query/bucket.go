@@ -0,0 +1,132 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+// Bucket is a single bucket of a classic histogram with its cumulative count
+type Bucket struct {
+	UpperBound float64
+	Count      float64
+}
+
+// BucketGroup contains the buckets of all series with the same labels, apart from le and the metric name
+type BucketGroup struct {
+	Metric  model.Metric
+	Buckets []Bucket
+}
+
+// BucketQuery returns the query for the bucket series of a classic histogram, e.g.
+// sum by (le, job) (rate(http_request_duration_seconds_bucket{job="api"}[5m]))
+// The metric may contain a selector, the _bucket suffix is added to the metric name.
+func BucketQuery(metric string, window string, groups []string) string {
+	name, selector := metric, ""
+
+	if i := strings.Index(metric, "{"); i >= 0 {
+		name, selector = metric[:i], metric[i:]
+	}
+
+	by := append([]string{model.BucketLabel}, groups...)
+
+	return fmt.Sprintf("sum by (%s) (rate(%s_bucket%s[%s]))", strings.Join(by, ", "), strings.TrimSpace(name), selector, window)
+}
+
+// GroupBuckets groups the samples of the bucket series by their labels,
+// the buckets of each group are sorted by their upper bound.
+func GroupBuckets(vector model.Vector) ([]BucketGroup, error) {
+	var groups []BucketGroup
+
+	index := make(map[model.Fingerprint]int)
+
+	for _, sample := range vector {
+		le, ok := sample.Metric[model.BucketLabel]
+		if !ok {
+			return nil, fmt.Errorf("series without %s label: %s", model.BucketLabel, sample.Metric)
+		}
+
+		upperBound, err := strconv.ParseFloat(string(le), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s label: %s", model.BucketLabel, sample.Metric)
+		}
+
+		metric := sample.Metric.Clone()
+		delete(metric, model.BucketLabel)
+		delete(metric, model.MetricNameLabel)
+
+		fp := metric.Fingerprint()
+
+		i, ok := index[fp]
+		if !ok {
+			i = len(groups)
+			index[fp] = i
+
+			groups = append(groups, BucketGroup{Metric: metric})
+		}
+
+		groups[i].Buckets = append(groups[i].Buckets, Bucket{UpperBound: upperBound, Count: float64(sample.Value)})
+	}
+
+	for _, g := range groups {
+		sort.Slice(g.Buckets, func(i, j int) bool {
+			return g.Buckets[i].UpperBound < g.Buckets[j].UpperBound
+		})
+	}
+
+	return groups, nil
+}
+
+// BucketQuantile calculates the φ-quantile of the buckets of a classic histogram
+// using linear interpolation, same as histogram_quantile().
+// The buckets have to be sorted by their upper bound and the last bucket has to be +Inf.
+// Without observations the result is NaN.
+func BucketQuantile(q float64, buckets []Bucket) (float64, error) {
+	if q < 0 || q > 1 {
+		return 0, fmt.Errorf("quantile must be between 0 and 1, got %v", q)
+	}
+
+	if len(buckets) < 2 {
+		return 0, errors.New("at least two buckets are required")
+	}
+
+	if !math.IsInf(buckets[len(buckets)-1].UpperBound, 1) {
+		return 0, errors.New("the +Inf bucket is missing")
+	}
+
+	observations := buckets[len(buckets)-1].Count
+	if observations == 0 {
+		return math.NaN(), nil
+	}
+
+	rank := q * observations
+
+	b := sort.Search(len(buckets)-1, func(i int) bool { return buckets[i].Count >= rank })
+
+	// The quantile lies within the +Inf bucket, the best guess is the highest finite upper bound
+	if b == len(buckets)-1 {
+		return buckets[len(buckets)-2].UpperBound, nil
+	}
+
+	if b == 0 && buckets[0].UpperBound <= 0 {
+		return buckets[0].UpperBound, nil
+	}
+
+	var start float64
+
+	end := buckets[b].UpperBound
+	count := buckets[b].Count
+
+	if b > 0 {
+		start = buckets[b-1].UpperBound
+		count -= buckets[b-1].Count
+		rank -= buckets[b-1].Count
+	}
+
+	return start + (end-start)*(rank/count), nil
+}
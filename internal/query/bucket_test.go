@@ -0,0 +1,83 @@
+package query
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestBucketQuery(t *testing.T) {
+	actual := BucketQuery(`http_request_duration_seconds{job="api"}`, "5m", []string{"instance"})
+	expected := `sum by (le, instance) (rate(http_request_duration_seconds_bucket{job="api"}[5m]))`
+
+	if actual != expected {
+		t.Error("\nActual: ", actual, "\nExpected: ", expected)
+	}
+}
+
+func TestGroupBuckets(t *testing.T) {
+	vector := model.Vector{
+		{Metric: model.Metric{"le": "+Inf", "job": "api"}, Value: 100},
+		{Metric: model.Metric{"le": "0.1", "job": "api"}, Value: 50},
+		{Metric: model.Metric{"le": "0.1", "job": "web"}, Value: 5},
+	}
+
+	groups, err := GroupBuckets(vector)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(groups) != 2 {
+		t.Fatal("\nActual: ", len(groups), "\nExpected: ", 2)
+	}
+
+	if groups[0].Metric["job"] != "api" || groups[0].Buckets[0].UpperBound != 0.1 || !math.IsInf(groups[0].Buckets[1].UpperBound, 1) {
+		t.Error("\nActual: ", groups[0], "\nExpected: sorted buckets of job api")
+	}
+
+	if _, err := GroupBuckets(model.Vector{{Metric: model.Metric{"job": "api"}}}); err == nil {
+		t.Error("Expected error for series without le label")
+	}
+}
+
+func TestBucketQuantile(t *testing.T) {
+	buckets := []Bucket{
+		{UpperBound: 0.1, Count: 50},
+		{UpperBound: 0.5, Count: 90},
+		{UpperBound: 1, Count: 100},
+		{UpperBound: math.Inf(1), Count: 100},
+	}
+
+	testcases := map[float64]float64{
+		0.25: 0.05,
+		0.5:  0.1,
+		0.7:  0.3,
+		0.95: 0.75,
+	}
+
+	for q, expected := range testcases {
+		actual, err := BucketQuantile(q, buckets)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if math.Abs(actual-expected) > 1e-9 {
+			t.Error("\nActual: ", actual, "\nExpected: ", expected, "\nQuantile: ", q)
+		}
+	}
+
+	// Observations in the +Inf bucket
+	inf := []Bucket{{UpperBound: 1, Count: 1}, {UpperBound: math.Inf(1), Count: 10}}
+	if actual, _ := BucketQuantile(0.99, inf); actual != 1 {
+		t.Error("\nActual: ", actual, "\nExpected: ", 1)
+	}
+
+	if actual, _ := BucketQuantile(0.5, []Bucket{{UpperBound: 1}, {UpperBound: math.Inf(1)}}); !math.IsNaN(actual) {
+		t.Error("\nActual: ", actual, "\nExpected: ", math.NaN())
+	}
+
+	if _, err := BucketQuantile(0.5, buckets[:3]); err == nil {
+		t.Error("Expected error for missing +Inf bucket")
+	}
+}
@@ -0,0 +1,56 @@
+package query
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/prometheus/common/model"
+)
+
+// Modes to compare a current value with a previous value
+const (
+	CompareAbsolute = "absolute"
+	ComparePercent  = "percent"
+)
+
+// ValidateCompareMode returns an error if the given comparison mode is not supported
+func ValidateCompareMode(mode string) error {
+	if mode != CompareAbsolute && mode != ComparePercent {
+		return fmt.Errorf("invalid comparison mode '%s', must be one of: %s, %s", mode, CompareAbsolute, ComparePercent)
+	}
+
+	return nil
+}
+
+// Difference returns the difference between the current and the previous value.
+// In percent mode the difference is relative to the previous value, a previous value of 0
+// results in +/-Inf unless the current value is 0 as well.
+func Difference(mode string, current, previous float64) float64 {
+	delta := current - previous
+
+	if mode != ComparePercent {
+		return delta
+	}
+
+	if previous == 0 {
+		if delta == 0 {
+			return 0
+		}
+
+		return math.Inf(int(math.Copysign(1, delta)))
+	}
+
+	return delta / math.Abs(previous) * 100
+}
+
+// IndexVector returns the samples of a vector indexed by their label set,
+// so that series of different evaluation times can be matched.
+func IndexVector(vector model.Vector) map[model.Fingerprint]*model.Sample {
+	index := make(map[model.Fingerprint]*model.Sample, len(vector))
+
+	for _, sample := range vector {
+		index[sample.Metric.Fingerprint()] = sample
+	}
+
+	return index
+}
@@ -0,0 +1,52 @@
+package query
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestDifference(t *testing.T) {
+	if actual := Difference(CompareAbsolute, 120, 100); actual != 20 {
+		t.Error("\nActual: ", actual, "\nExpected: ", 20)
+	}
+
+	if actual := Difference(ComparePercent, 120, 100); actual != 20 {
+		t.Error("\nActual: ", actual, "\nExpected: ", 20)
+	}
+
+	if actual := Difference(ComparePercent, -50, -100); actual != 50 {
+		t.Error("\nActual: ", actual, "\nExpected: ", 50)
+	}
+
+	if actual := Difference(ComparePercent, 0, 0); actual != 0 {
+		t.Error("\nActual: ", actual, "\nExpected: ", 0)
+	}
+
+	if actual := Difference(ComparePercent, -1, 0); !math.IsInf(actual, -1) {
+		t.Error("\nActual: ", actual, "\nExpected: ", math.Inf(-1))
+	}
+
+	if ValidateCompareMode("relative") == nil {
+		t.Error("Expected error for invalid mode")
+	}
+}
+
+func TestIndexVector(t *testing.T) {
+	vector := model.Vector{
+		{Metric: model.Metric{"instance": "db01"}, Value: 1},
+		{Metric: model.Metric{"instance": "db02"}, Value: 2},
+	}
+
+	index := IndexVector(vector)
+
+	sample, ok := index[model.Metric{"instance": "db02"}.Fingerprint()]
+	if !ok || sample.Value != 2 {
+		t.Error("\nActual: ", sample, "\nExpected: ", vector[1])
+	}
+
+	if _, ok := index[model.Metric{"instance": "db03"}.Fingerprint()]; ok {
+		t.Error("Expected no sample for db03")
+	}
+}
@@ -0,0 +1,30 @@
+package query
+
+import (
+	"errors"
+
+	"github.com/NETWAYS/go-check"
+	"github.com/prometheus/common/model"
+)
+
+// DutyCycle returns the share (0-100) of the values that violate the threshold.
+// A missing threshold is never violated.
+func DutyCycle(values []model.SamplePair, threshold *check.Threshold) (float64, error) {
+	if len(values) == 0 {
+		return 0, errors.New("no samples in the window")
+	}
+
+	if threshold == nil {
+		return 0, nil
+	}
+
+	var violations int
+
+	for _, v := range values {
+		if threshold.DoesViolate(float64(v.Value)) {
+			violations++
+		}
+	}
+
+	return float64(violations) / float64(len(values)) * 100, nil
+}
@@ -0,0 +1,44 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/NETWAYS/go-check"
+	"github.com/prometheus/common/model"
+)
+
+func TestDutyCycle(t *testing.T) {
+	values := []model.SamplePair{
+		{Timestamp: model.TimeFromUnix(960), Value: 50},
+		{Timestamp: model.TimeFromUnix(970), Value: 95},
+		{Timestamp: model.TimeFromUnix(980), Value: 85},
+		{Timestamp: model.TimeFromUnix(990), Value: 60},
+		{Timestamp: model.TimeFromUnix(1000), Value: 99},
+	}
+
+	warning, _ := check.ParseThreshold("80")
+	critical, _ := check.ParseThreshold("90")
+
+	share, err := DutyCycle(values, warning)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if share != 60 {
+		t.Error("\nActual: ", share, "\nExpected: ", 60)
+	}
+
+	share, _ = DutyCycle(values, critical)
+	if share != 40 {
+		t.Error("\nActual: ", share, "\nExpected: ", 40)
+	}
+
+	share, _ = DutyCycle(values, nil)
+	if share != 0 {
+		t.Error("\nActual: ", share, "\nExpected: ", 0)
+	}
+
+	if _, err := DutyCycle(nil, warning); err == nil {
+		t.Error("Expected error for no samples")
+	}
+}
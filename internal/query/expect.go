@@ -0,0 +1,99 @@
+package query
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+// ParseExpectedSeries parses the labels of an expected series in the format <label>=<value>[,<label>=<value>...]
+// e.g. instance=db01,job=node
+func ParseExpectedSeries(spec string) (model.LabelSet, error) {
+	labels := model.LabelSet{}
+
+	for _, part := range strings.Split(spec, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("expected <label>=<value> in expected series: %s", spec)
+		}
+
+		if !isValidName(name) {
+			return nil, fmt.Errorf("invalid label name '%s' in expected series: %s", name, spec)
+		}
+
+		labels[model.LabelName(name)] = model.LabelValue(value)
+	}
+
+	return labels, nil
+}
+
+// ReadExpectedSeries reads the expected series from a file with one series per line.
+// Empty lines and lines starting with # are ignored.
+func ReadExpectedSeries(r io.Reader) ([]model.LabelSet, error) {
+	var expected []model.LabelSet
+
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		labels, err := ParseExpectedSeries(line)
+		if err != nil {
+			return nil, err
+		}
+
+		expected = append(expected, labels)
+	}
+
+	return expected, scanner.Err()
+}
+
+// FindMissingSeries compares the expected series with the series of a result.
+// A series matches an expected series if it has all of its labels with the same values.
+// It returns the expected series without a matching series and the series not matching any expected series.
+func FindMissingSeries(expected []model.LabelSet, series []model.Metric) ([]model.LabelSet, []model.Metric) {
+	var missing []model.LabelSet
+
+	found := make([]bool, len(series))
+
+	for _, labels := range expected {
+		present := false
+
+		for i, metric := range series {
+			if containsLabels(metric, labels) {
+				present = true
+				found[i] = true
+			}
+		}
+
+		if !present {
+			missing = append(missing, labels)
+		}
+	}
+
+	var unexpected []model.Metric
+
+	for i, metric := range series {
+		if !found[i] {
+			unexpected = append(unexpected, metric)
+		}
+	}
+
+	return missing, unexpected
+}
+
+func containsLabels(metric model.Metric, labels model.LabelSet) bool {
+	for name, value := range labels {
+		if metric[name] != value {
+			return false
+		}
+	}
+
+	return true
+}
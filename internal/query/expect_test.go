@@ -0,0 +1,61 @@
+package query
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestParseExpectedSeries(t *testing.T) {
+	labels, err := ParseExpectedSeries("instance=db01, job=node")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := model.LabelSet{"instance": "db01", "job": "node"}
+	if !labels.Equal(expected) {
+		t.Error("\nActual: ", labels, "\nExpected: ", expected)
+	}
+
+	for _, spec := range []string{"db01", "=db01", "in-stance=db01"} {
+		if _, err := ParseExpectedSeries(spec); err == nil {
+			t.Error("Expected error for: ", spec)
+		}
+	}
+}
+
+func TestReadExpectedSeries(t *testing.T) {
+	input := "# databases\ninstance=db01\n\ninstance=db02,job=mysqld\n"
+
+	expected, err := ReadExpectedSeries(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(expected) != 2 || expected[1]["job"] != "mysqld" {
+		t.Error("\nActual: ", expected, "\nExpected: ", "[{instance=\"db01\"} {instance=\"db02\", job=\"mysqld\"}]")
+	}
+}
+
+func TestFindMissingSeries(t *testing.T) {
+	expected := []model.LabelSet{
+		{"instance": "db01"},
+		{"instance": "db02"},
+	}
+
+	series := []model.Metric{
+		{"__name__": "up", "instance": "db01", "job": "mysqld"},
+		{"__name__": "up", "instance": "db03", "job": "mysqld"},
+	}
+
+	missing, unexpected := FindMissingSeries(expected, series)
+
+	if len(missing) != 1 || missing[0]["instance"] != "db02" {
+		t.Error("\nActual: ", missing, "\nExpected: ", expected[1:])
+	}
+
+	if len(unexpected) != 1 || unexpected[0]["instance"] != "db03" {
+		t.Error("\nActual: ", unexpected, "\nExpected: ", series[1:])
+	}
+}
@@ -0,0 +1,75 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+// LabelFilter includes or excludes series by the values of their labels
+type LabelFilter struct {
+	Include []*Matcher
+	Exclude []*Matcher
+}
+
+// ParseLabelFilter parses the include and exclude filters in the format <label>=<regex>,
+// e.g. instance=db.* or mountpoint=/var/.*
+func ParseLabelFilter(include, exclude []string) (*LabelFilter, error) {
+	f := &LabelFilter{}
+
+	for _, spec := range include {
+		m, err := parseLabelRegex(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		f.Include = append(f.Include, m)
+	}
+
+	for _, spec := range exclude {
+		m, err := parseLabelRegex(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		f.Exclude = append(f.Exclude, m)
+	}
+
+	return f, nil
+}
+
+func parseLabelRegex(spec string) (*Matcher, error) {
+	name, value, ok := strings.Cut(spec, "=")
+	if !ok || !isValidName(name) {
+		return nil, fmt.Errorf("expected <label>=<regex> in label filter: %s", spec)
+	}
+
+	return NewMatcher(name, MatchRegexp, value)
+}
+
+// Keep returns true if the metric matches any of the include filters and none of the exclude filters.
+// Without include filters all metrics are included.
+func (f *LabelFilter) Keep(metric model.Metric) bool {
+	if f == nil {
+		return true
+	}
+
+	for _, m := range f.Exclude {
+		if m.Matches(metric) {
+			return false
+		}
+	}
+
+	if len(f.Include) == 0 {
+		return true
+	}
+
+	for _, m := range f.Include {
+		if m.Matches(metric) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,45 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestLabelFilter(t *testing.T) {
+	f, err := ParseLabelFilter([]string{"instance=db.*", "job=node"}, []string{"instance=db03"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := map[string]bool{
+		"db01":      true,
+		"db03":      false,
+		"web01":     false,
+		"olddb01":   false,
+		"db02:9100": true,
+	}
+
+	for instance, expected := range testcases {
+		if actual := f.Keep(model.Metric{"instance": model.LabelValue(instance)}); actual != expected {
+			t.Error("\nActual: ", actual, "\nExpected: ", expected, "\nInstance: ", instance)
+		}
+	}
+
+	if !f.Keep(model.Metric{"instance": "web01", "job": "node"}) {
+		t.Error("Expected web01 of job node to be included")
+	}
+
+	var empty *LabelFilter
+	if !empty.Keep(model.Metric{"instance": "db01"}) {
+		t.Error("Expected nil filter to keep all series")
+	}
+
+	if _, err := ParseLabelFilter([]string{"instance"}, nil); err == nil {
+		t.Error("Expected error for missing regex")
+	}
+
+	if _, err := ParseLabelFilter(nil, []string{"instance=db[0"}); err == nil {
+		t.Error("Expected error for invalid regex")
+	}
+}
@@ -0,0 +1,87 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// Directions in which a series crosses the forecast target
+const (
+	ForecastUp   = "up"
+	ForecastDown = "down"
+)
+
+// ValidateForecastDirection returns an error if the given direction is not supported
+func ValidateForecastDirection(direction string) error {
+	if direction != ForecastUp && direction != ForecastDown {
+		return fmt.Errorf("invalid forecast direction '%s', must be one of: %s, %s", direction, ForecastUp, ForecastDown)
+	}
+
+	return nil
+}
+
+// LinearRegression fits a line through the values using the least squares method, same as predict_linear().
+// It returns the slope per second and the value of the line at the given time.
+func LinearRegression(values []model.SamplePair, at time.Time) (slope float64, intercept float64, err error) {
+	if len(values) < 2 {
+		return 0, 0, errors.New("linear regression requires at least two samples")
+	}
+
+	var sumX, sumY, sumXY, sumX2 float64
+
+	n := float64(len(values))
+
+	for _, v := range values {
+		// Seconds relative to the given time to keep the numbers small
+		x := v.Timestamp.Time().Sub(at).Seconds()
+		y := float64(v.Value)
+
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumX2 += x * x
+	}
+
+	covXY := sumXY - sumX*sumY/n
+	varX := sumX2 - sumX*sumX/n
+
+	if varX == 0 {
+		return 0, 0, errors.New("linear regression requires samples with different timestamps")
+	}
+
+	slope = covXY / varX
+	intercept = sumY/n - slope*sumX/n
+
+	return slope, intercept, nil
+}
+
+// TimeToTarget estimates the seconds until the values cross the target in the given direction,
+// based on a linear regression of the values. It returns 0 if the last value already crossed the
+// target and +Inf if the trend never reaches the target.
+func TimeToTarget(values []model.SamplePair, now time.Time, target float64, direction string) (float64, error) {
+	slope, intercept, err := LinearRegression(values, now)
+	if err != nil {
+		return 0, err
+	}
+
+	last := float64(values[len(values)-1].Value)
+
+	if direction == ForecastDown {
+		// Mirror the values, so that we only need to handle upwards crossings
+		slope, intercept, last, target = -slope, -intercept, -last, -target
+	}
+
+	if last >= target {
+		return 0, nil
+	}
+
+	if slope <= 0 {
+		return math.Inf(1), nil
+	}
+
+	return math.Max(0, (target-intercept)/slope), nil
+}
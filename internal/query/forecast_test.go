@@ -0,0 +1,82 @@
+package query
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestLinearRegression(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	// Grows by 2 per second and would be 100 at 'now'
+	values := []model.SamplePair{
+		{Timestamp: model.TimeFromUnix(970), Value: 40},
+		{Timestamp: model.TimeFromUnix(980), Value: 60},
+		{Timestamp: model.TimeFromUnix(990), Value: 80},
+	}
+
+	slope, intercept, err := LinearRegression(values, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if slope != 2 || intercept != 100 {
+		t.Error("\nActual: ", slope, intercept, "\nExpected: ", 2, 100)
+	}
+
+	if _, _, err := LinearRegression(values[:1], now); err == nil {
+		t.Error("Expected error for a single sample")
+	}
+
+	if _, _, err := LinearRegression([]model.SamplePair{values[0], values[0]}, now); err == nil {
+		t.Error("Expected error for samples with the same timestamp")
+	}
+}
+
+func TestTimeToTarget(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	rising := []model.SamplePair{
+		{Timestamp: model.TimeFromUnix(970), Value: 40},
+		{Timestamp: model.TimeFromUnix(980), Value: 60},
+		{Timestamp: model.TimeFromUnix(990), Value: 80},
+	}
+
+	falling := []model.SamplePair{
+		{Timestamp: model.TimeFromUnix(970), Value: 80},
+		{Timestamp: model.TimeFromUnix(980), Value: 60},
+		{Timestamp: model.TimeFromUnix(990), Value: 40},
+	}
+
+	testcases := []struct {
+		values    []model.SamplePair
+		target    float64
+		direction string
+		expected  float64
+	}{
+		{rising, 200, ForecastUp, 50},
+		{rising, 80, ForecastUp, 0},
+		{rising, 0, ForecastDown, math.Inf(1)},
+		{falling, 0, ForecastDown, 10},
+		{falling, 200, ForecastUp, math.Inf(1)},
+		{falling, 50, ForecastDown, 0},
+	}
+
+	for _, tc := range testcases {
+		actual, err := TimeToTarget(tc.values, now, tc.target, tc.direction)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual != tc.expected {
+			t.Error("\nActual: ", actual, "\nExpected: ", tc.expected)
+		}
+	}
+
+	if ValidateForecastDirection("sideways") == nil {
+		t.Error("Expected error for invalid direction")
+	}
+}
@@ -0,0 +1,102 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/NETWAYS/go-check"
+	"github.com/prometheus/common/model"
+)
+
+// Views of a native histogram that can be evaluated against the thresholds
+const (
+	HistogramViewQuantile = "quantile"
+	HistogramViewCount    = "count"
+	HistogramViewSum      = "sum"
+)
+
+// Quantile is a φ-quantile of a histogram with its own thresholds.
+// A nil threshold means the global threshold is used.
+type Quantile struct {
+	Quantile float64
+	Warning  *check.Threshold
+	Critical *check.Threshold
+}
+
+// ParseQuantile parses a quantile (0-1) in the format <quantile>[:w=<threshold>,c=<threshold>]
+// e.g. 0.99:w=0.5,c=1
+func ParseQuantile(spec string) (*Quantile, error) {
+	quantile, list, found := strings.Cut(spec, ":")
+
+	q, err := strconv.ParseFloat(strings.TrimSpace(quantile), 64)
+	if err != nil || q < 0 || q > 1 {
+		return nil, fmt.Errorf("expected a quantile between 0 and 1 in histogram quantile: %s", spec)
+	}
+
+	hq := &Quantile{Quantile: q}
+
+	if found {
+		hq.Warning, hq.Critical, err = parseThresholdList(list, spec)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return hq, nil
+}
+
+// Label returns the value of the quantile label of the quantile, e.g. 0.99
+func (q *Quantile) Label() model.LabelValue {
+	return model.LabelValue(strconv.FormatFloat(q.Quantile, 'f', -1, 64))
+}
+
+// ValidateHistogramView returns an error if the given histogram view is not supported
+func ValidateHistogramView(view string) error {
+	if view != HistogramViewQuantile && view != HistogramViewCount && view != HistogramViewSum {
+		return fmt.Errorf("invalid histogram view '%s', must be one of: %s, %s, %s",
+			view, HistogramViewQuantile, HistogramViewCount, HistogramViewSum)
+	}
+
+	return nil
+}
+
+// HistogramQuantile calculates the φ-quantile of a native histogram.
+// The observations are assumed to be spread evenly within a bucket (linear interpolation).
+// A histogram without observations results in NaN, same as histogram_quantile().
+func HistogramQuantile(q float64, h *model.SampleHistogram) (float64, error) {
+	if q < 0 || q > 1 {
+		return 0, fmt.Errorf("quantile must be between 0 and 1, got %v", q)
+	}
+
+	if h == nil || h.Count == 0 || len(h.Buckets) == 0 {
+		return math.NaN(), nil
+	}
+
+	var total float64
+	for _, b := range h.Buckets {
+		total += float64(b.Count)
+	}
+
+	rank := q * total
+
+	var cumulative float64
+
+	for _, b := range h.Buckets {
+		count := float64(b.Count)
+
+		if count == 0 || cumulative+count < rank {
+			cumulative += count
+			continue
+		}
+
+		lower := float64(b.Lower)
+		upper := float64(b.Upper)
+
+		return lower + (upper-lower)*(rank-cumulative)/count, nil
+	}
+
+	// Only reached with rounding errors, the quantile is the upper bound of the last bucket
+	return float64(h.Buckets[len(h.Buckets)-1].Upper), nil
+}
@@ -0,0 +1,78 @@
+package query
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestHistogramQuantile(t *testing.T) {
+	h := &model.SampleHistogram{
+		Count: 100,
+		Sum:   25,
+		Buckets: model.HistogramBuckets{
+			{Boundaries: 0, Lower: 0, Upper: 0.1, Count: 50},
+			{Boundaries: 0, Lower: 0.1, Upper: 0.5, Count: 40},
+			{Boundaries: 0, Lower: 0.5, Upper: 1, Count: 10},
+		},
+	}
+
+	testcases := map[float64]float64{
+		0:    0,
+		0.25: 0.05,
+		0.5:  0.1,
+		0.7:  0.3,
+		0.95: 0.75,
+		1:    1,
+	}
+
+	for q, expected := range testcases {
+		actual, err := HistogramQuantile(q, h)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if math.Abs(actual-expected) > 1e-9 {
+			t.Error("\nActual: ", actual, "\nExpected: ", expected, "\nQuantile: ", q)
+		}
+	}
+
+	if _, err := HistogramQuantile(1.5, h); err == nil {
+		t.Error("Expected error for invalid quantile")
+	}
+
+	if actual, _ := HistogramQuantile(0.5, &model.SampleHistogram{}); !math.IsNaN(actual) {
+		t.Error("\nActual: ", actual, "\nExpected: ", math.NaN())
+	}
+
+	if ValidateHistogramView("max") == nil {
+		t.Error("Expected error for invalid view")
+	}
+}
+
+func TestParseQuantile(t *testing.T) {
+	q, err := ParseQuantile("0.99:w=0.5,c=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if q.Quantile != 0.99 || q.Label() != "0.99" || q.Warning.String() != "0.5" || q.Critical.String() != "1" {
+		t.Error("\nActual: ", q, "\nExpected: ", "0.99 0.5 1")
+	}
+
+	q, err = ParseQuantile("0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if q.Quantile != 0.5 || q.Warning != nil || q.Critical != nil {
+		t.Error("\nActual: ", q, "\nExpected: ", "0.5 <nil> <nil>")
+	}
+
+	for _, spec := range []string{"", "q99", "99", "1.5", "-0.1", "0.99:", "0.99:x=1", "0.99:w=abc"} {
+		if _, err := ParseQuantile(spec); err == nil {
+			t.Error("Expected error for", spec)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package query
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// LibraryQuery is a query of a query library, with its default thresholds and perfdata label
+type LibraryQuery struct {
+	Description   string `yaml:"description"`
+	Expr          string `yaml:"expr"`
+	Warning       string `yaml:"warning"`
+	Critical      string `yaml:"critical"`
+	PerfdataLabel string `yaml:"perfdata_label"`
+}
+
+// Library is a collection of queries by their name
+type Library struct {
+	Queries map[string]LibraryQuery `yaml:"queries"`
+}
+
+// ReadLibrary reads a query library from YAML, e.g.:
+//
+//	queries:
+//	  disk_usage:
+//	    description: Disk usage of ${instance}
+//	    expr: 100 - node_filesystem_avail_bytes{instance="${instance}"} / node_filesystem_size_bytes{instance="${instance}"} * 100
+//	    warning: "80"
+//	    critical: "90"
+//	    perfdata_label: "{{.mountpoint}}"
+func ReadLibrary(r io.Reader) (*Library, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var l Library
+
+	if err := yaml.UnmarshalStrict(data, &l); err != nil {
+		return nil, fmt.Errorf("could not parse query library: %w", err)
+	}
+
+	for name, q := range l.Queries {
+		if strings.TrimSpace(q.Expr) == "" {
+			return nil, fmt.Errorf("query '%s' in query library has no expr", name)
+		}
+	}
+
+	return &l, nil
+}
+
+// Lookup returns the query with the given name
+func (l *Library) Lookup(name string) (LibraryQuery, error) {
+	q, ok := l.Queries[name]
+	if !ok {
+		names := make([]string, 0, len(l.Queries))
+		for n := range l.Queries {
+			names = append(names, n)
+		}
+
+		slices.Sort(names)
+
+		return LibraryQuery{}, fmt.Errorf("query '%s' not found in query library, available queries: %s", name, strings.Join(names, ", "))
+	}
+
+	return q, nil
+}
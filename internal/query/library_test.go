@@ -0,0 +1,53 @@
+package query
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadLibrary(t *testing.T) {
+	library := `
+queries:
+  disk_usage:
+    description: Disk usage of ${instance}
+    expr: disk_used_percent{instance="${instance}"}
+    warning: "80"
+    critical: "90"
+    perfdata_label: "{{.mountpoint}}"
+  up:
+    expr: up
+`
+
+	l, err := ReadLibrary(strings.NewReader(library))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q, err := l.Lookup("disk_usage")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := LibraryQuery{
+		Description:   "Disk usage of ${instance}",
+		Expr:          `disk_used_percent{instance="${instance}"}`,
+		Warning:       "80",
+		Critical:      "90",
+		PerfdataLabel: "{{.mountpoint}}",
+	}
+
+	if q != expected {
+		t.Error("\nActual: ", q, "\nExpected: ", expected)
+	}
+
+	_, err = l.Lookup("load")
+	if err == nil || err.Error() != "query 'load' not found in query library, available queries: disk_usage, up" {
+		t.Error("\nActual: ", err, "\nExpected: ", "query 'load' not found in query library, available queries: disk_usage, up")
+	}
+
+	for _, invalid := range []string{"queries: [", "queries:\n  up:\n    warning: 1\n", "queries:\n  up:\n    expr: up\n    threshold: 1\n"} {
+		if _, err := ReadLibrary(strings.NewReader(invalid)); err == nil {
+			t.Error("Expected error for", invalid)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+// MatchType is the type of a label matcher, same as in PromQL
+type MatchType string
+
+const (
+	MatchEqual     MatchType = "="
+	MatchNotEqual  MatchType = "!="
+	MatchRegexp    MatchType = "=~"
+	MatchNotRegexp MatchType = "!~"
+)
+
+// Matcher matches the value of a label, e.g. mountpoint="/var" or instance=~"db.*"
+type Matcher struct {
+	Name  model.LabelName
+	Type  MatchType
+	Value string
+	re    *regexp.Regexp
+}
+
+// NewMatcher returns a Matcher, regular expressions are anchored like in PromQL
+func NewMatcher(name string, t MatchType, value string) (*Matcher, error) {
+	m := &Matcher{
+		Name:  model.LabelName(name),
+		Type:  t,
+		Value: value,
+	}
+
+	switch t {
+	case MatchEqual, MatchNotEqual:
+	case MatchRegexp, MatchNotRegexp:
+		re, err := regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression for label %s: %w", name, err)
+		}
+
+		m.re = re
+	default:
+		return nil, fmt.Errorf("invalid match type '%s'", t)
+	}
+
+	return m, nil
+}
+
+// Matches returns true if the label of the metric matches.
+// A missing label is treated as an empty value, same as in PromQL.
+func (m *Matcher) Matches(metric model.Metric) bool {
+	value := string(metric[m.Name])
+
+	switch m.Type {
+	case MatchEqual:
+		return value == m.Value
+	case MatchNotEqual:
+		return value != m.Value
+	case MatchRegexp:
+		return m.re.MatchString(value)
+	case MatchNotRegexp:
+		return !m.re.MatchString(value)
+	}
+
+	return false
+}
+
+// String returns the PromQL representation of the Matcher
+func (m *Matcher) String() string {
+	return fmt.Sprintf("%s%s%q", m.Name, m.Type, m.Value)
+}
+
+// MatchesAll returns true if all matchers match the metric
+func MatchesAll(matchers []*Matcher, metric model.Metric) bool {
+	for _, m := range matchers {
+		if !m.Matches(metric) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ParseMatchers parses a PromQL style selector, e.g. {mountpoint="/var", env=~"prod|stage"}
+func ParseMatchers(selector string) ([]*Matcher, error) {
+	matchers, rest, err := parseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(rest) != "" {
+		return nil, fmt.Errorf("unexpected '%s' after selector", rest)
+	}
+
+	return matchers, nil
+}
+
+// parseSelector parses the selector at the beginning of the input and returns the remaining input
+func parseSelector(input string) ([]*Matcher, string, error) {
+	s := strings.TrimSpace(input)
+
+	if !strings.HasPrefix(s, "{") {
+		return nil, "", fmt.Errorf("selector must start with '{': %s", input)
+	}
+
+	s = s[1:]
+
+	var matchers []*Matcher
+
+	for {
+		s = strings.TrimSpace(s)
+
+		if strings.HasPrefix(s, "}") {
+			return matchers, s[1:], nil
+		}
+
+		// Label name
+		i := 0
+		for i < len(s) && isLabelNameChar(s[i], i == 0) {
+			i++
+		}
+
+		if i == 0 {
+			return nil, "", fmt.Errorf("expected label name in selector: %s", input)
+		}
+
+		name := s[:i]
+		s = strings.TrimSpace(s[i:])
+
+		// Match type, the two character operators need to be checked first
+		var t MatchType
+
+		for _, op := range []MatchType{MatchRegexp, MatchNotRegexp, MatchNotEqual, MatchEqual} {
+			if strings.HasPrefix(s, string(op)) {
+				t = op
+				break
+			}
+		}
+
+		if t == "" {
+			return nil, "", fmt.Errorf("expected match operator after label %s in selector: %s", name, input)
+		}
+
+		s = strings.TrimSpace(s[len(t):])
+
+		// Quoted label value
+		value, rest, err := parseQuoted(s)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid value for label %s in selector: %w", name, err)
+		}
+
+		m, err := NewMatcher(name, t, value)
+		if err != nil {
+			return nil, "", err
+		}
+
+		matchers = append(matchers, m)
+
+		s = strings.TrimSpace(rest)
+
+		switch {
+		case strings.HasPrefix(s, ","):
+			s = s[1:]
+		case strings.HasPrefix(s, "}"):
+			continue
+		default:
+			return nil, "", fmt.Errorf("expected ',' or '}' in selector: %s", input)
+		}
+	}
+}
+
+// parseQuoted parses a double quoted or backtick quoted string at the beginning of the input
+func parseQuoted(s string) (string, string, error) {
+	if s == "" || (s[0] != '"' && s[0] != '`') {
+		return "", "", fmt.Errorf("expected quoted string: %s", s)
+	}
+
+	quote := s[0]
+
+	for i := 1; i < len(s); i++ {
+		if s[i] == '\\' && quote == '"' {
+			i++
+			continue
+		}
+
+		if s[i] == quote {
+			value, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", "", err
+			}
+
+			return value, s[i+1:], nil
+		}
+	}
+
+	return "", "", fmt.Errorf("unterminated quoted string: %s", s)
+}
+
+func isLabelNameChar(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9')
+}
@@ -0,0 +1,83 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestParseMatchers(t *testing.T) {
+	matchers, err := ParseMatchers(`{mountpoint="/var", env=~"prod|stage" ,job!="node", instance!~` + "`db.*`" + `}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(matchers) != 4 {
+		t.Fatal("\nActual: ", len(matchers), "\nExpected: ", 4)
+	}
+
+	expected := []string{`mountpoint="/var"`, `env=~"prod|stage"`, `job!="node"`, `instance!~"db.*"`}
+	for i, m := range matchers {
+		if m.String() != expected[i] {
+			t.Error("\nActual: ", m.String(), "\nExpected: ", expected[i])
+		}
+	}
+
+	matchers, err = ParseMatchers(`{path="C:\\Temp}, \"quoted\""}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if matchers[0].Value != `C:\Temp}, "quoted"` {
+		t.Error("\nActual: ", matchers[0].Value, "\nExpected: ", `C:\Temp}, "quoted"`)
+	}
+
+	matchers, err = ParseMatchers(`{}`)
+	if err != nil || len(matchers) != 0 {
+		t.Error("Expected empty selector to be valid")
+	}
+}
+
+func TestParseMatchers_Invalid(t *testing.T) {
+	invalid := []string{
+		`mountpoint="/var"`,
+		`{mountpoint}`,
+		`{mountpoint="/var"`,
+		`{mountpoint=/var}`,
+		`{mountpoint=~"(/var"}`,
+		`{mountpoint="/var"} foo`,
+		`{1abc="foo"}`,
+	}
+
+	for _, s := range invalid {
+		if _, err := ParseMatchers(s); err == nil {
+			t.Error("Expected error for: ", s)
+		}
+	}
+}
+
+func TestMatchesAll(t *testing.T) {
+	metric := model.Metric{"mountpoint": "/var", "env": "production"}
+
+	testcases := map[string]bool{
+		`{mountpoint="/var"}`:                   true,
+		`{mountpoint="/"}`:                      false,
+		`{mountpoint="/var", env=~"prod.*"}`:    true,
+		`{mountpoint="/var", env=~"prod"}`:      false,
+		`{env!~"stag.*"}`:                       true,
+		`{env!="production"}`:                   false,
+		`{device=""}`:                           true,
+		`{mountpoint=~"/var|/tmp", device!=""}`: false,
+	}
+
+	for selector, expected := range testcases {
+		matchers, err := ParseMatchers(selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if MatchesAll(matchers, metric) != expected {
+			t.Error(selector, "\nActual: ", !expected, "\nExpected: ", expected)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/NETWAYS/go-check"
+)
+
+// NamedQuery is a PromQL expression with an optional name and its own thresholds
+type NamedQuery struct {
+	Name     string
+	Expr     string
+	Warning  *check.Threshold
+	Critical *check.Threshold
+}
+
+// ParseNamedQuery parses a query in the format [<name>=]<expr>, e.g. load=node_load1.
+// If the part before the first '=' is not a valid name, the whole input is used as expression,
+// so that queries like up{job="node"} or foo==1 remain unnamed.
+func ParseNamedQuery(spec string) NamedQuery {
+	name, expr, found := strings.Cut(spec, "=")
+	if !found || !isValidName(name) || strings.HasPrefix(expr, "=") {
+		return NamedQuery{Expr: spec}
+	}
+
+	return NamedQuery{Name: name, Expr: expr}
+}
+
+// ParseQueryThreshold parses the thresholds for a named query in the format <name>:w=<threshold>,c=<threshold>
+// e.g. load:w=5,c=10
+func ParseQueryThreshold(spec string) (name string, warning, critical *check.Threshold, err error) {
+	name, list, found := strings.Cut(spec, ":")
+	if !found || !isValidName(name) {
+		return "", nil, nil, fmt.Errorf("expected <name>:w=<threshold>,c=<threshold> in query threshold: %s", spec)
+	}
+
+	warning, critical, err = parseThresholdList(list, spec)
+
+	return name, warning, critical, err
+}
+
+func isValidName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i := range len(name) {
+		if !isLabelNameChar(name[i], i == 0) {
+			return false
+		}
+	}
+
+	return true
+}
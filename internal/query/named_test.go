@@ -0,0 +1,51 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestParseNamedQuery(t *testing.T) {
+	testcases := map[string]NamedQuery{
+		"load=node_load1":             {Name: "load", Expr: "node_load1"},
+		"up{job=\"node\"}":            {Expr: "up{job=\"node\"}"},
+		"foo==1":                      {Expr: "foo==1"},
+		"foo == 1":                    {Expr: "foo == 1"},
+		"errors=sum(rate(x[5m]))":     {Name: "errors", Expr: "sum(rate(x[5m]))"},
+		"up":                          {Expr: "up"},
+		"1abc=up":                     {Expr: "1abc=up"},
+		"ready=up{job=\"node\"} == 1": {Name: "ready", Expr: "up{job=\"node\"} == 1"},
+	}
+
+	for spec, expected := range testcases {
+		actual := ParseNamedQuery(spec)
+		if actual.Name != expected.Name || actual.Expr != expected.Expr {
+			t.Error(spec, "\nActual: ", actual, "\nExpected: ", expected)
+		}
+	}
+}
+
+func TestParseQueryThreshold(t *testing.T) {
+	name, warn, crit, err := ParseQueryThreshold("load:w=5,c=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name != "load" || warn.String() != "5" || crit.String() != "10" {
+		t.Error("\nActual: ", name, warn, crit, "\nExpected: load 5 10")
+	}
+
+	name, warn, crit, err = ParseQueryThreshold("uptime:c=300:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name != "uptime" || warn != nil || crit.String() != "300:" {
+		t.Error("\nActual: ", name, warn, crit, "\nExpected: uptime <nil> 300:")
+	}
+
+	for _, spec := range []string{"load", "load:5", "{job=\"node\"}:w=5", "load:w=foo"} {
+		if _, _, _, err := ParseQueryThreshold(spec); err == nil {
+			t.Error("Expected error for: ", spec)
+		}
+	}
+}
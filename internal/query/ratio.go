@@ -0,0 +1,75 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/prometheus/common/model"
+)
+
+// VectorPair is a numerator series and its matching denominator series
+type VectorPair struct {
+	Metric      model.Metric
+	Numerator   *model.Sample
+	Denominator *model.Sample
+}
+
+// JoinKey returns the key used to match the series of two vectors, similar to on() in PromQL.
+// If no labels are given, all labels except the metric name are used.
+func JoinKey(metric model.Metric, labels []string) model.Fingerprint {
+	key := make(model.Metric, len(metric))
+
+	if len(labels) == 0 {
+		for name, value := range metric {
+			if name != model.MetricNameLabel {
+				key[name] = value
+			}
+		}
+
+		return key.Fingerprint()
+	}
+
+	for _, name := range labels {
+		if value, ok := metric[model.LabelName(name)]; ok {
+			key[model.LabelName(name)] = value
+		}
+	}
+
+	return key.Fingerprint()
+}
+
+// JoinVectors matches each numerator series with a denominator series on the given labels.
+// Multiple numerator series may match the same denominator series (many-to-one),
+// numerator series without a matching denominator series are returned separately.
+func JoinVectors(numerator, denominator model.Vector, labels []string) ([]VectorPair, []*model.Sample, error) {
+	denominators := make(map[model.Fingerprint]*model.Sample, len(denominator))
+
+	for _, sample := range denominator {
+		key := JoinKey(sample.Metric, labels)
+
+		if _, ok := denominators[key]; ok {
+			return nil, nil, fmt.Errorf("multiple denominator series match the labels of %s", sample.Metric)
+		}
+
+		denominators[key] = sample
+	}
+
+	pairs := make([]VectorPair, 0, len(numerator))
+
+	var unmatched []*model.Sample
+
+	for _, sample := range numerator {
+		d, ok := denominators[JoinKey(sample.Metric, labels)]
+		if !ok {
+			unmatched = append(unmatched, sample)
+			continue
+		}
+
+		// The metric name is dropped, since the ratio is a different metric
+		metric := sample.Metric.Clone()
+		delete(metric, model.MetricNameLabel)
+
+		pairs = append(pairs, VectorPair{Metric: metric, Numerator: sample, Denominator: d})
+	}
+
+	return pairs, unmatched, nil
+}
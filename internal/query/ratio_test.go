@@ -0,0 +1,65 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestJoinVectors(t *testing.T) {
+	numerator := model.Vector{
+		{Metric: model.Metric{"__name__": "errors", "job": "api", "code": "500"}, Value: 5},
+		{Metric: model.Metric{"__name__": "errors", "job": "api", "code": "503"}, Value: 10},
+		{Metric: model.Metric{"__name__": "errors", "job": "web", "code": "500"}, Value: 1},
+	}
+
+	denominator := model.Vector{
+		{Metric: model.Metric{"__name__": "requests", "job": "api"}, Value: 100},
+	}
+
+	pairs, unmatched, err := JoinVectors(numerator, denominator, []string{"job"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pairs) != 2 || len(unmatched) != 1 {
+		t.Fatal("\nActual: ", len(pairs), len(unmatched), "\nExpected: ", 2, 1)
+	}
+
+	if pairs[1].Numerator.Value != 10 || pairs[1].Denominator.Value != 100 {
+		t.Error("\nActual: ", pairs[1])
+	}
+
+	if pairs[0].Metric.String() != `{code="500", job="api"}` {
+		t.Error("\nActual: ", pairs[0].Metric.String(), "\nExpected: ", `{code="500", job="api"}`)
+	}
+
+	if unmatched[0].Metric["job"] != "web" {
+		t.Error("\nActual: ", unmatched[0])
+	}
+}
+
+func TestJoinVectors_AllLabels(t *testing.T) {
+	numerator := model.Vector{
+		{Metric: model.Metric{"__name__": "errors", "job": "api"}, Value: 5},
+	}
+
+	denominator := model.Vector{
+		{Metric: model.Metric{"__name__": "requests", "job": "api"}, Value: 100},
+		{Metric: model.Metric{"__name__": "requests", "job": "web"}, Value: 100},
+	}
+
+	pairs, unmatched, err := JoinVectors(numerator, denominator, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pairs) != 1 || len(unmatched) != 0 {
+		t.Fatal("\nActual: ", len(pairs), len(unmatched), "\nExpected: ", 1, 0)
+	}
+
+	_, _, err = JoinVectors(numerator, denominator, []string{"instance"})
+	if err == nil {
+		t.Error("Expected error for duplicate denominator series")
+	}
+}
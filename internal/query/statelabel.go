@@ -0,0 +1,56 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/NETWAYS/go-check"
+)
+
+// StateLabelMap maps label values to a state, the label values are matched case-insensitive
+type StateLabelMap map[string]int
+
+// DefaultStateLabelMap returns the label values that are mapped by default,
+// these are the same as for the --label-key-state of alerts
+func DefaultStateLabelMap() StateLabelMap {
+	return StateLabelMap{
+		"ok":       check.OK,
+		"warning":  check.Warning,
+		"critical": check.Critical,
+		"unknown":  check.Unknown,
+	}
+}
+
+// ParseStateLabelMap parses a state label map in the format <label value>=<state>[,...]
+// e.g. healthy=OK,degraded=WARNING,failed=CRITICAL
+// The entries are added to the default map and the states are converted with the given function.
+func ParseStateLabelMap(spec string, parseState func(string) (int, error)) (StateLabelMap, error) {
+	m := DefaultStateLabelMap()
+
+	if spec == "" {
+		return m, nil
+	}
+
+	for _, part := range strings.Split(spec, ",") {
+		value, state, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || strings.TrimSpace(value) == "" {
+			return nil, fmt.Errorf("expected <label value>=<state> in state label map: %s", spec)
+		}
+
+		s, err := parseState(strings.TrimSpace(state))
+		if err != nil {
+			return nil, fmt.Errorf("invalid state '%s' in state label map: %s", state, spec)
+		}
+
+		m[strings.ToLower(strings.TrimSpace(value))] = s
+	}
+
+	return m, nil
+}
+
+// Lookup returns the state of a label value
+func (m StateLabelMap) Lookup(value string) (int, bool) {
+	state, ok := m[strings.ToLower(value)]
+
+	return state, ok
+}
@@ -0,0 +1,47 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestParseStateLabelMap(t *testing.T) {
+	m, err := ParseStateLabelMap("healthy=OK, Degraded=WARNING,critical=OK", parseTestState)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := map[string]int{
+		"HEALTHY":  0,
+		"degraded": 1,
+		"Warning":  1,
+		"critical": 0,
+		"unknown":  3,
+	}
+
+	for value, expected := range testcases {
+		if state, ok := m.Lookup(value); !ok || state != expected {
+			t.Error("\nActual: ", state, "\nExpected: ", expected)
+		}
+	}
+
+	if _, ok := m.Lookup("failed"); ok {
+		t.Error("Expected no mapping for failed")
+	}
+
+	for _, spec := range []string{"healthy", "=OK", "healthy=BROKEN"} {
+		if _, err := ParseStateLabelMap(spec, parseTestState); err == nil {
+			t.Error("Expected error for", spec)
+		}
+	}
+}
+
+func TestDefaultStateLabelMap(t *testing.T) {
+	m, err := ParseStateLabelMap("", parseTestState)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state, ok := m.Lookup("CRITICAL"); !ok || state != 2 {
+		t.Error("\nActual: ", state, "\nExpected: ", 2)
+	}
+}
@@ -0,0 +1,87 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/NETWAYS/go-check"
+	"github.com/prometheus/common/model"
+)
+
+// ThresholdOverride contains the thresholds for all series matching the label matchers.
+// A nil threshold means the global threshold is used.
+type ThresholdOverride struct {
+	Matchers []*Matcher
+	Warning  *check.Threshold
+	Critical *check.Threshold
+}
+
+// ParseThresholdOverride parses a threshold override in the format <selector>:w=<threshold>,c=<threshold>
+// e.g. {mountpoint="/var"}:w=80,c=90
+func ParseThresholdOverride(spec string) (*ThresholdOverride, error) {
+	matchers, rest, err := parseSelector(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	rest = strings.TrimSpace(rest)
+	if !strings.HasPrefix(rest, ":") {
+		return nil, fmt.Errorf("expected ':' after selector in threshold: %s", spec)
+	}
+
+	warning, critical, err := parseThresholdList(rest[1:], spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ThresholdOverride{Matchers: matchers, Warning: warning, Critical: critical}, nil
+}
+
+// parseThresholdList parses a list of thresholds in the format w=<threshold>,c=<threshold>,
+// a threshold that is not part of the list is returned as nil.
+func parseThresholdList(list string, spec string) (warning, critical *check.Threshold, err error) {
+	for _, part := range strings.Split(list, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return nil, nil, fmt.Errorf("expected w=<threshold> or c=<threshold> in threshold: %s", spec)
+		}
+
+		t, err := check.ParseThreshold(kv[1])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch kv[0] {
+		case "w", "warning":
+			warning = t
+		case "c", "critical":
+			critical = t
+		default:
+			return nil, nil, fmt.Errorf("unknown threshold '%s' in threshold: %s", kv[0], spec)
+		}
+	}
+
+	return warning, critical, nil
+}
+
+// SelectThresholds returns the thresholds of the first override matching the metric,
+// falling back to the given global thresholds.
+func SelectThresholds(overrides []*ThresholdOverride, metric model.Metric, warning, critical *check.Threshold) (*check.Threshold, *check.Threshold) {
+	for _, o := range overrides {
+		if !MatchesAll(o.Matchers, metric) {
+			continue
+		}
+
+		if o.Warning != nil {
+			warning = o.Warning
+		}
+
+		if o.Critical != nil {
+			critical = o.Critical
+		}
+
+		break
+	}
+
+	return warning, critical
+}
@@ -0,0 +1,73 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/NETWAYS/go-check"
+	"github.com/prometheus/common/model"
+)
+
+func TestParseThresholdOverride(t *testing.T) {
+	o, err := ParseThresholdOverride(`{mountpoint="/var"}:w=80,c=90`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(o.Matchers) != 1 || o.Warning.String() != "80" || o.Critical.String() != "90" {
+		t.Error("\nActual: ", o)
+	}
+
+	o, err = ParseThresholdOverride(`{env=~"stag.*"} : critical=@10:20`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if o.Warning != nil || o.Critical.String() != "@10:20" {
+		t.Error("\nActual: ", o)
+	}
+
+	invalid := []string{
+		`{mountpoint="/var"}`,
+		`{mountpoint="/var"}:80`,
+		`{mountpoint="/var"}:x=80`,
+		`{mountpoint="/var"}:w=foo`,
+		`mountpoint="/var":w=80`,
+	}
+
+	for _, s := range invalid {
+		if _, err := ParseThresholdOverride(s); err == nil {
+			t.Error("Expected error for: ", s)
+		}
+	}
+}
+
+func TestSelectThresholds(t *testing.T) {
+	warn, _ := check.ParseThreshold("10")
+	crit, _ := check.ParseThreshold("20")
+
+	var overrides []*ThresholdOverride
+
+	for _, spec := range []string{`{mountpoint="/var"}:w=80,c=90`, `{mountpoint=~"/.*"}:c=30`} {
+		o, err := ParseThresholdOverride(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		overrides = append(overrides, o)
+	}
+
+	w, c := SelectThresholds(overrides, model.Metric{"mountpoint": "/var"}, warn, crit)
+	if w.String() != "80" || c.String() != "90" {
+		t.Error("\nActual: ", w, c, "\nExpected: 80 90")
+	}
+
+	w, c = SelectThresholds(overrides, model.Metric{"mountpoint": "/"}, warn, crit)
+	if w.String() != "10" || c.String() != "30" {
+		t.Error("\nActual: ", w, c, "\nExpected: 10 30")
+	}
+
+	w, c = SelectThresholds(overrides, model.Metric{"device": "sda"}, warn, crit)
+	if w != warn || c != crit {
+		t.Error("\nActual: ", w, c, "\nExpected: 10 20")
+	}
+}
@@ -0,0 +1,46 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// ParseTime parses the evaluation time of a query, either as RFC3339 timestamp (e.g. 2026-10-17T12:00:00Z),
+// unix timestamp (e.g. 1791800000) or relative to now (e.g. -2m). An empty string returns now.
+func ParseTime(spec string, now time.Time) (time.Time, error) {
+	spec = strings.TrimSpace(spec)
+
+	if spec == "" || spec == "now" {
+		return now, nil
+	}
+
+	// Relative to now, model.ParseDuration does not support signs
+	if strings.HasPrefix(spec, "-") || strings.HasPrefix(spec, "+") {
+		d, err := model.ParseDuration(spec[1:])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid relative time '%s': %w", spec, err)
+		}
+
+		if spec[0] == '-' {
+			return now.Add(-time.Duration(d)), nil
+		}
+
+		return now.Add(time.Duration(d)), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, spec); err == nil {
+		return t, nil
+	}
+
+	if f, err := strconv.ParseFloat(spec, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*float64(time.Second))).UTC(), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time '%s', must be a RFC3339 or unix timestamp or relative to now e.g. -2m", spec)
+}
@@ -0,0 +1,37 @@
+package query
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	testcases := map[string]time.Time{
+		"":                     now,
+		"now":                  now,
+		"-2m":                  time.Unix(880, 0),
+		"+1h":                  time.Unix(4600, 0),
+		"1696589905":           time.Unix(1696589905, 0),
+		"1696589905.5":         time.Unix(1696589905, 500000000),
+		"2023-10-06T10:58:25Z": time.Unix(1696589905, 0),
+	}
+
+	for spec, expected := range testcases {
+		actual, err := ParseTime(spec, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !actual.Equal(expected) {
+			t.Error("\nActual: ", actual, "\nExpected: ", expected)
+		}
+	}
+
+	for _, spec := range []string{"yesterday", "-2", "-", "2023-10-06", "NaN"} {
+		if _, err := ParseTime(spec, now); err == nil {
+			t.Error("Expected error for", spec)
+		}
+	}
+}
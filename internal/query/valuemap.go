@@ -0,0 +1,57 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ValueMapping is the state and the display text of a mapped value
+type ValueMapping struct {
+	State int
+	Text  string
+}
+
+// ValueMap maps exact values to a state and a display text
+type ValueMap map[float64]ValueMapping
+
+// ParseValueMap parses a value map in the format <value>=<state>[:<text>][,...]
+// e.g. 0=CRITICAL:down,1=OK:up,2=WARNING:degraded
+// The states are converted with the given function.
+func ParseValueMap(spec string, parseState func(string) (int, error)) (ValueMap, error) {
+	m := ValueMap{}
+
+	for _, part := range strings.Split(spec, ",") {
+		value, mapping, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("expected <value>=<state>[:<text>] in value map: %s", spec)
+		}
+
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value '%s' in value map: %s", value, spec)
+		}
+
+		if _, ok := m[v]; ok {
+			return nil, fmt.Errorf("duplicate value '%s' in value map: %s", value, spec)
+		}
+
+		state, text, _ := strings.Cut(mapping, ":")
+
+		s, err := parseState(strings.TrimSpace(state))
+		if err != nil {
+			return nil, fmt.Errorf("invalid state '%s' in value map: %s", state, spec)
+		}
+
+		m[v] = ValueMapping{State: s, Text: text}
+	}
+
+	return m, nil
+}
+
+// Lookup returns the mapping of a value
+func (m ValueMap) Lookup(value float64) (ValueMapping, bool) {
+	mapping, ok := m[value]
+
+	return mapping, ok
+}
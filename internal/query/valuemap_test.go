@@ -0,0 +1,44 @@
+package query
+
+import (
+	"errors"
+	"testing"
+)
+
+func parseTestState(state string) (int, error) {
+	switch state {
+	case "OK":
+		return 0, nil
+	case "WARNING":
+		return 1, nil
+	case "CRITICAL":
+		return 2, nil
+	}
+
+	return 3, errors.New("invalid state")
+}
+
+func TestParseValueMap(t *testing.T) {
+	m, err := ParseValueMap("0=CRITICAL:down, 1=OK:up,2=WARNING", parseTestState)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mapping, ok := m.Lookup(0); !ok || mapping.State != 2 || mapping.Text != "down" {
+		t.Error("\nActual: ", mapping, "\nExpected: ", ValueMapping{State: 2, Text: "down"})
+	}
+
+	if mapping, ok := m.Lookup(2); !ok || mapping.State != 1 || mapping.Text != "" {
+		t.Error("\nActual: ", mapping, "\nExpected: ", ValueMapping{State: 1})
+	}
+
+	if _, ok := m.Lookup(3); ok {
+		t.Error("Expected no mapping for 3")
+	}
+
+	for _, spec := range []string{"0", "a=OK", "0=BROKEN", "0=OK,0=CRITICAL"} {
+		if _, err := ParseValueMap(spec, parseTestState); err == nil {
+			t.Error("Expected error for: ", spec)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// placeholder matches the variables of a query in the format ${name} or ${name:<escaping>}
+var placeholder = regexp.MustCompile(`\$\{([^}:]*)(?::([^}]*))?\}`)
+
+// Escaping of a placeholder, the values are escaped for PromQL strings by default
+const (
+	EscapeRegex = "regex"
+)
+
+// ParseVars parses variables in the format <name>=<value>, e.g. instance=db01
+func ParseVars(specs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(specs))
+
+	for _, spec := range specs {
+		name, value, ok := strings.Cut(spec, "=")
+		if !ok || !isValidName(name) {
+			return nil, fmt.Errorf("expected <name>=<value> in variable: %s", spec)
+		}
+
+		if _, ok := vars[name]; ok {
+			return nil, fmt.Errorf("duplicate variable: %s", name)
+		}
+
+		vars[name] = value
+	}
+
+	return vars, nil
+}
+
+// Substitute replaces the placeholders ${name} in the expression with the values of the variables.
+// The values are escaped for PromQL strings, so placeholders are meant to be used within quotes
+// e.g. up{instance="${instance}"}. With ${name:regex} the regular expression metacharacters are escaped as well,
+// for the use in =~ and !~ matchers e.g. up{instance=~"${instance:regex}.*"}.
+// Placeholders without a variable remain unchanged, e.g. the ${1} of label_replace(),
+// placeholders of a variable with an unknown escaping return an error.
+func Substitute(expr string, vars map[string]string) (string, error) {
+	if len(vars) == 0 {
+		return expr, nil
+	}
+
+	var invalid []string
+
+	result := placeholder.ReplaceAllStringFunc(expr, func(m string) string {
+		match := placeholder.FindStringSubmatch(m)
+		name, escaping := match[1], match[2]
+
+		value, ok := vars[name]
+		if !ok {
+			return m
+		}
+
+		switch escaping {
+		case "":
+			return escapeString(value)
+		case EscapeRegex:
+			return escapeString(regexp.QuoteMeta(value))
+		default:
+			invalid = append(invalid, m)
+
+			return m
+		}
+	})
+
+	if len(invalid) > 0 {
+		return "", fmt.Errorf("invalid placeholders in query, must be ${name} or ${name:%s}: %s", EscapeRegex, strings.Join(invalid, ", "))
+	}
+
+	return result, nil
+}
+
+// SubstituteText replaces the placeholders ${name} in a text with the unescaped values of the variables,
+// regardless of their escaping. Placeholders without a variable remain unchanged.
+func SubstituteText(text string, vars map[string]string) string {
+	return placeholder.ReplaceAllStringFunc(text, func(m string) string {
+		if value, ok := vars[placeholder.FindStringSubmatch(m)[1]]; ok {
+			return value
+		}
+
+		return m
+	})
+}
+
+// escapeString escapes a value for a double-quoted PromQL string, which uses the same escaping as Go
+func escapeString(value string) string {
+	quoted := strconv.Quote(value)
+
+	return quoted[1 : len(quoted)-1]
+}
@@ -0,0 +1,85 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestParseVars(t *testing.T) {
+	vars, err := ParseVars([]string{"instance=db01", "path=/var=log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if vars["instance"] != "db01" || vars["path"] != "/var=log" {
+		t.Error("\nActual: ", vars, "\nExpected: ", map[string]string{"instance": "db01", "path": "/var=log"})
+	}
+
+	for _, specs := range [][]string{{"instance"}, {"1x=a"}, {"a=1", "a=2"}} {
+		if _, err := ParseVars(specs); err == nil {
+			t.Error("Expected error for", specs)
+		}
+	}
+}
+
+func TestSubstitute(t *testing.T) {
+	vars := map[string]string{
+		"instance": "db01",
+		"evil":     `"} or vector(1) or up{a="`,
+		"path":     `C:\data`,
+		"host":     "web.(1)+",
+	}
+
+	testcases := map[string]string{
+		`up{instance="${instance}"}`:                   `up{instance="db01"}`,
+		`up{job="${evil}"}`:                            `up{job="\"} or vector(1) or up{a=\""}`,
+		`disk{path="${path}", instance="${instance}"}`: `disk{path="C:\\data", instance="db01"}`,
+		`up`:                                  `up`,
+		`up{instance=~"${instance:regex}.*"}`: `up{instance=~"db01.*"}`,
+		`up{path=~"${path:regex}"}`:           `up{path=~"C:\\\\data"}`,
+		`up{host=~"${host:regex}"}`:           `up{host=~"web\\.\\(1\\)\\+"}`,
+	}
+
+	for expr, expected := range testcases {
+		actual, err := Substitute(expr, vars)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual != expected {
+			t.Error("\nActual: ", actual, "\nExpected: ", expected)
+		}
+	}
+
+	// Placeholders without a variable remain unchanged
+	expr := `label_replace(up{instance="${instance}", job="${job}"}, "host", "${1}", "instance", "(.*):.*")`
+	expected := `label_replace(up{instance="db01", job="${job}"}, "host", "${1}", "instance", "(.*):.*")`
+
+	actual, err := Substitute(expr, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual != expected {
+		t.Error("\nActual: ", actual, "\nExpected: ", expected)
+	}
+
+	// Without variables the expression is not substituted at all
+	if actual, err := Substitute(`up{instance="${instance:json}"}`, nil); err != nil || actual != `up{instance="${instance:json}"}` {
+		t.Error("\nActual: ", actual, err, "\nExpected: ", `up{instance="${instance:json}"}`)
+	}
+}
+
+func TestSubstituteText(t *testing.T) {
+	actual := SubstituteText(`Disk usage of "${instance}" ${job}`, map[string]string{"instance": "db01"})
+
+	if actual != `Disk usage of "db01" ${job}` {
+		t.Error("\nActual: ", actual, "\nExpected: ", `Disk usage of "db01" ${job}`)
+	}
+}
+
+func TestSubstituteInvalid(t *testing.T) {
+	_, err := Substitute(`up{instance="${instance:json}", job="${}"}`, map[string]string{"instance": "db01"})
+	if err == nil || err.Error() != "invalid placeholders in query, must be ${name} or ${name:regex}: ${instance:json}" {
+		t.Error("\nActual: ", err, "\nExpected: ", "invalid placeholders in query, must be ${name} or ${name:regex}: ${instance:json}")
+	}
+}